@@ -21,7 +21,12 @@ func InitDB(repoPath string) error {
 	// For Phase 1, we'll use dolt CLI directly via shell commands
 	// This is simpler and avoids the sql-server requirement
 	fmt.Fprintf(os.Stderr, "[DB] Using Dolt CLI directly (repo: %s)\n", repoPath)
-	return nil
+
+	// Outside a Dolt repository there is nothing to migrate
+	if _, err := GetRepoPath(); err != nil {
+		return nil
+	}
+	return EnsureSchema()
 }
 
 // ExecDoltSQL executes a SQL command via the dolt CLI
@@ -124,3 +129,43 @@ func DoltCommit(message string) error {
 
 	return nil
 }
+
+// RunDolt runs an arbitrary dolt CLI command in the repository and returns its output
+func RunDolt(args ...string) (string, error) {
+	repoPath, err := GetRepoPath()
+	if err != nil {
+		return "", err
+	}
+
+	cmd := exec.Command("dolt", args...)
+	cmd.Dir = repoPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("dolt %s failed: %w\nOutput: %s", args[0], err, string(output))
+	}
+
+	return string(output), nil
+}
+
+// ExecDoltSQLScript pipes a multi-statement SQL script into dolt sql
+func ExecDoltSQLScript(script string) error {
+	repoPath, err := GetRepoPath()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("dolt", "sql")
+	cmd.Dir = repoPath
+	cmd.Stdin = strings.NewReader(script)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("dolt sql script failed: %w\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// DoltTag creates a tag at HEAD with the given message
+func DoltTag(name, message string) error {
+	_, err := RunDolt("tag", "-m", message, name)
+	return err
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// RunGit runs a local git command in dir and returns its trimmed output.
+// Only local plumbing commands are used, so nothing touches the network.
+func RunGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w\nOutput: %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GitToplevel returns the root of the git working tree containing dir
+func GitToplevel(dir string) (string, error) {
+	return RunGit(dir, "rev-parse", "--show-toplevel")
+}
+
+// GitHead returns the full hash of HEAD in the working tree containing dir
+func GitHead(dir string) (string, error) {
+	return RunGit(dir, "rev-parse", "HEAD")
+}
+
+// GitResolveCommit expands a commit-ish (short SHA, branch, tag) to a full hash
+func GitResolveCommit(dir, rev string) (string, error) {
+	hash, err := RunGit(dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown git commit %q", rev)
+	}
+	return hash, nil
+}
+
+// GitCommitFiles lists the files a commit changed, relative to the repo root
+func GitCommitFiles(dir, hash string) ([]string, error) {
+	output, err := RunGit(dir, "diff-tree", "--no-commit-id", "--name-only", "-r", "--root", hash)
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// GitCommitsTouching returns the hashes of every commit that changed path
+func GitCommitsTouching(dir, path string) ([]string, error) {
+	output, err := RunGit(dir, "log", "--format=%H", "--", path)
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// GitRelativePath converts path (absolute or relative to dir) to a
+// slash-separated path relative to the repository root
+func GitRelativePath(dir, path string) (string, error) {
+	top, err := GitToplevel(dir)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return "", err
+	}
+	// Resolve symlinks on both sides so /tmp vs /private/tmp style aliases match
+	if resolved, err := filepath.EvalSymlinks(top); err == nil {
+		top = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		path = filepath.Join(resolved, filepath.Base(path))
+	}
+	rel, err := filepath.Rel(top, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the git repository %s", path, top)
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// schemaColumn is a column added to a table after its first release. Stores
+// created from an older schema.sql get it on the next InitDB.
+type schemaColumn struct {
+	table      string
+	name       string
+	definition string
+	index      string // Optional index created along with the column
+}
+
+var schemaColumns = []schemaColumn{
+	{"memories", "content_hash", "CHAR(64)", "idx_memories_content_hash (team_id, content_hash)"},
+	{"memories", "reviewed_by", "VARCHAR(255)", ""},
+	{"memories", "reviewed_at", "TIMESTAMP NULL", ""},
+	{"memories", "expires_at", "TIMESTAMP NULL", "idx_memories_expires (expires_at)"},
+	{"decisions", "owner_id", "VARCHAR(255)", ""},
+	{"decisions", "status", "ENUM('proposed', 'active', 'reverted', 'superseded') DEFAULT 'active'", "idx_decisions_status (status)"},
+	{"decisions", "rationale", "TEXT", ""},
+	{"decisions", "alternatives", "JSON", ""},
+	{"decisions", "confidence", "FLOAT NULL DEFAULT NULL", ""},
+	{"decisions", "superseded_by", "VARCHAR(36)", ""},
+	{"decisions", "status_reason", "TEXT", ""},
+	{"decisions", "git_commit", "VARCHAR(40)", "idx_decisions_git_commit (git_commit)"},
+	{"decisions", "files", "JSON", ""},
+	{"decisions", "commit_hash", "VARCHAR(64)", ""},
+}
+
+// schemaTable is a table added after the first release, created on the next
+// InitDB for stores that predate it
+type schemaTable struct {
+	name       string
+	definition string
+}
+
+var schemaTables = []schemaTable{
+	{"conflicts", `CREATE TABLE IF NOT EXISTS conflicts (
+		id VARCHAR(36) PRIMARY KEY,
+		memory_a VARCHAR(36) NOT NULL,
+		memory_b VARCHAR(36) NOT NULL,
+		team_id VARCHAR(255),
+		similarity FLOAT,
+		method VARCHAR(20),
+		reason TEXT,
+		status ENUM('open', 'resolved', 'dismissed') DEFAULT 'open',
+		resolution VARCHAR(50),
+		detected_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		resolved_at TIMESTAMP NULL,
+		UNIQUE KEY uniq_conflict_pair (memory_a, memory_b)
+	)`},
+	{"tag_aliases", `CREATE TABLE IF NOT EXISTS tag_aliases (
+		alias VARCHAR(255) PRIMARY KEY,
+		tag VARCHAR(255) NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`},
+	{"memory_credit", `CREATE TABLE IF NOT EXISTS memory_credit (
+		memory_id VARCHAR(36) NOT NULL,
+		decision_id VARCHAR(36) NOT NULL,
+		weight FLOAT NOT NULL,
+		outcome FLOAT NOT NULL,
+		successes FLOAT NOT NULL,
+		failures FLOAT NOT NULL,
+		priority_delta FLOAT NOT NULL,
+		recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (memory_id, decision_id)
+	)`},
+	{"pairing_actions", `CREATE TABLE IF NOT EXISTS pairing_actions (
+		id VARCHAR(36) PRIMARY KEY,
+		task_id VARCHAR(255) NOT NULL,
+		source VARCHAR(255),
+		action TEXT NOT NULL,
+		payload JSON,
+		recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		INDEX idx_pairing_actions_task (task_id)
+	)`},
+}
+
+// memoryStatusType is the current definition of memories.status
+const memoryStatusType = "ENUM('verified', 'under_review', 'deprecated', 'archived') DEFAULT 'verified'"
+
+// EnsureSchema brings an older store up to the current schema: missing
+// tables are created and the memories and decisions tables get their new
+// columns. It is idempotent and only writes, in one Dolt commit, when
+// something is missing. Stores without a memories table yet are left for
+// schema.sql to create.
+func EnsureSchema() error {
+	names := []string{"'memories'", "'decisions'"}
+	for _, t := range schemaTables {
+		names = append(names, "'"+t.name+"'")
+	}
+	output, err := ExecDoltSQL(fmt.Sprintf(`
+		SELECT table_name AS tbl, column_name AS col, column_type AS typ, column_default AS def
+		FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name IN (%s)
+	`, strings.Join(names, ", ")))
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	type column struct{ typ, def string }
+	tables := make(map[string]map[string]column)
+	for _, row := range result.Rows {
+		table := strings.ToLower(fmt.Sprint(row["tbl"]))
+		if tables[table] == nil {
+			tables[table] = make(map[string]column)
+		}
+		c := column{typ: strings.ToLower(fmt.Sprint(row["typ"]))}
+		// NULL columns are omitted from Dolt's JSON rows
+		if def, ok := row["def"]; ok && def != nil && !strings.EqualFold(fmt.Sprint(def), "null") {
+			c.def = fmt.Sprint(def)
+		}
+		tables[table][strings.ToLower(fmt.Sprint(row["col"]))] = c
+	}
+
+	if _, ok := tables["memories"]; !ok {
+		return nil
+	}
+
+	var statements []string
+	for _, t := range schemaTables {
+		if _, ok := tables[t.name]; !ok {
+			statements = append(statements, t.definition)
+		}
+	}
+	for _, c := range schemaColumns {
+		columns, ok := tables[c.table]
+		if !ok {
+			continue
+		}
+		if _, ok := columns[c.name]; ok {
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.name, c.definition)
+		if c.index != "" {
+			stmt += ", ADD INDEX " + c.index
+		}
+		statements = append(statements, stmt)
+	}
+
+	if status, ok := tables["memories"]["status"]; ok && !strings.Contains(status.typ, "'archived'") {
+		statements = append(statements, "ALTER TABLE memories MODIFY COLUMN status "+memoryStatusType)
+	}
+
+	// Outcomes used to default to 0.0, which can't be told apart from a
+	// recorded failure. Pending is NULL now; legacy 0.0 rows nobody gave
+	// feedback on were never recorded. Gated on the old default so a real
+	// 0.0 recorded after the migration is never touched.
+	if outcome, ok := tables["decisions"]["outcome"]; ok && outcome.def != "" {
+		statements = append(statements,
+			"ALTER TABLE decisions MODIFY COLUMN outcome FLOAT NULL DEFAULT NULL",
+			"UPDATE decisions SET outcome = NULL WHERE outcome = 0 AND (feedback IS NULL OR feedback = '')",
+		)
+	}
+
+	if len(statements) == 0 {
+		return nil
+	}
+
+	if err := ExecDoltSQLScript(strings.Join(statements, ";\n") + ";\n"); err != nil {
+		return fmt.Errorf("failed to migrate schema: %w", err)
+	}
+	if err := DoltCommit(fmt.Sprintf("Migrate schema (%d change(s))", len(statements))); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+	fmt.Fprintf(os.Stderr, "[DB] Migrated schema: %d change(s)\n", len(statements))
+	return nil
+}
@@ -6,9 +6,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 )
 
+const (
+	DefaultOllamaURL   = "http://localhost:11434"
+	DefaultOllamaModel = "qwen2.5-coder:1.5b"
+)
+
 type OllamaClient struct {
 	BaseURL string
 	Model   string
@@ -36,6 +43,23 @@ func NewOllamaClient(baseURL, model string) *OllamaClient {
 	}
 }
 
+// NewOllamaClientFromEnv builds a client from OLLAMA_HOST and AMI_OLLAMA_MODEL,
+// falling back to the local defaults
+func NewOllamaClientFromEnv() *OllamaClient {
+	baseURL := os.Getenv("OLLAMA_HOST")
+	if baseURL == "" {
+		baseURL = DefaultOllamaURL
+	} else if !strings.Contains(baseURL, "://") {
+		// Ollama itself accepts a bare host:port here
+		baseURL = "http://" + baseURL
+	}
+	model := os.Getenv("AMI_OLLAMA_MODEL")
+	if model == "" {
+		model = DefaultOllamaModel
+	}
+	return NewOllamaClient(baseURL, model)
+}
+
 // Generate sends a request to Ollama with a circuit breaker pattern
 func (c *OllamaClient) Generate(ctx context.Context, prompt string) (string, error) {
 	reqBody := GenerateRequest{
@@ -32,15 +32,16 @@ func (c Category) IsValid() bool {
 type Status string
 
 const (
-	StatusVerified     Status = "verified"
+	StatusVerified    Status = "verified"
 	StatusUnderReview Status = "under_review"
 	StatusDeprecated  Status = "deprecated"
+	StatusArchived    Status = "archived"
 )
 
 // IsValid checks if the status is valid
 func (s Status) IsValid() bool {
 	switch s {
-	case StatusVerified, StatusUnderReview, StatusDeprecated:
+	case StatusVerified, StatusUnderReview, StatusDeprecated, StatusArchived:
 		return true
 	default:
 		return false
@@ -73,20 +74,23 @@ func (t Tags) Value() (driver.Value, error) {
 
 // Memory represents a stored memory
 type Memory struct {
-	ID              string    `json:"id"`
-	Content         string    `json:"content"`
-	OwnerID         string    `json:"owner_id"`
-	Category        Category  `json:"category"`
-	Priority        float64   `json:"priority"`
-	CreatedAt       time.Time `json:"created_at"`
-	AccessedAt      time.Time `json:"accessed_at"`
-	AccessCount     int       `json:"access_count"`
-	Source          string    `json:"source,omitempty"`
-	Tags            Tags      `json:"tags,omitempty"`
-	Embedding       []float32 `json:"embedding,omitempty"`
-	EmbeddingCached bool      `json:"embedding_cached"`
-	Status          Status    `json:"status,omitempty"`
-	TeamID          string    `json:"team_id,omitempty"`
+	ID              string     `json:"id"`
+	Content         string     `json:"content"`
+	OwnerID         string     `json:"owner_id"`
+	Category        Category   `json:"category"`
+	Priority        float64    `json:"priority"`
+	CreatedAt       time.Time  `json:"created_at"`
+	AccessedAt      time.Time  `json:"accessed_at"`
+	AccessCount     int        `json:"access_count"`
+	Source          string     `json:"source,omitempty"`
+	Tags            Tags       `json:"tags,omitempty"`
+	Embedding       []float32  `json:"embedding,omitempty"`
+	EmbeddingCached bool       `json:"embedding_cached"`
+	Status          Status     `json:"status,omitempty"`
+	TeamID          string     `json:"team_id,omitempty"`
+	ReviewedBy      string     `json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
 }
 
 // Helper functions for type conversion
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"sort"
+	"time"
+)
+
+// CalibrationOptions controls CalibrateOwners
+type CalibrationOptions struct {
+	OwnerID string        // Only this owner; empty for all
+	Bins    int           // Reliability curve bins across 0-1 (default 5)
+	Since   time.Duration // Only decisions tracked within this window; 0 for all
+}
+
+// ReliabilityBin is one point on a reliability curve: decisions whose stated
+// confidence fell in [Lower, Upper), the last bin including 1.0, and how they
+// actually turned out
+type ReliabilityBin struct {
+	Lower          float64 `json:"lower"`
+	Upper          float64 `json:"upper"`
+	Count          int     `json:"count"`
+	MeanConfidence float64 `json:"mean_confidence"`
+	MeanOutcome    float64 `json:"mean_outcome"`
+}
+
+// Calibration scores one owner's stated confidence against later outcomes
+type Calibration struct {
+	OwnerID        string           `json:"owner_id"`
+	Decisions      int              `json:"decisions"` // Decisions with both a confidence and an outcome
+	Brier          float64          `json:"brier"`     // Mean (confidence - outcome)^2; 0 is perfect
+	MeanConfidence float64          `json:"mean_confidence"`
+	MeanOutcome    float64          `json:"mean_outcome"`
+	Bias           float64          `json:"bias"` // MeanConfidence - MeanOutcome; positive is over-confident
+	Curve          []ReliabilityBin `json:"curve"`
+}
+
+// CalibrateOwners computes Brier scores and reliability curves per owner
+// from decisions that stated a confidence and later got an outcome.
+// Outcomes are graded 0-1 rather than pass/fail, so the Brier score is taken
+// against the outcome value itself. Owners are sorted worst calibrated first.
+func CalibrateOwners(opts CalibrationOptions) ([]Calibration, error) {
+	if opts.Bins <= 0 {
+		opts.Bins = 5
+	}
+
+	decisions, err := ListDecisions("")
+	if err != nil {
+		return nil, err
+	}
+
+	var cutoff time.Time
+	if opts.Since > 0 {
+		cutoff = time.Now().Add(-opts.Since)
+	}
+
+	byOwner := make(map[string][]Decision)
+	for _, d := range decisions {
+		if d.Confidence == nil || d.Outcome == nil {
+			continue
+		}
+		if opts.OwnerID != "" && d.OwnerID != opts.OwnerID {
+			continue
+		}
+		if !cutoff.IsZero() && d.CreatedAt.Before(cutoff) {
+			continue
+		}
+		byOwner[d.OwnerID] = append(byOwner[d.OwnerID], d)
+	}
+
+	results := make([]Calibration, 0, len(byOwner))
+	for owner, ds := range byOwner {
+		results = append(results, calibrate(owner, ds, opts.Bins))
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Brier != results[j].Brier {
+			return results[i].Brier > results[j].Brier
+		}
+		return results[i].OwnerID < results[j].OwnerID
+	})
+
+	return results, nil
+}
+
+// calibrate scores one owner's decisions, each of which has a confidence and
+// an outcome
+func calibrate(owner string, decisions []Decision, bins int) Calibration {
+	c := Calibration{OwnerID: owner, Decisions: len(decisions)}
+
+	curve := make([]ReliabilityBin, bins)
+	for i := range curve {
+		curve[i].Lower = float64(i) / float64(bins)
+		curve[i].Upper = float64(i+1) / float64(bins)
+	}
+
+	for _, d := range decisions {
+		p, o := *d.Confidence, *d.Outcome
+		c.Brier += (p - o) * (p - o)
+		c.MeanConfidence += p
+		c.MeanOutcome += o
+
+		b := &curve[min(int(p*float64(bins)), bins-1)]
+		b.Count++
+		b.MeanConfidence += p
+		b.MeanOutcome += o
+	}
+
+	n := float64(len(decisions))
+	c.Brier /= n
+	c.MeanConfidence /= n
+	c.MeanOutcome /= n
+	c.Bias = c.MeanConfidence - c.MeanOutcome
+
+	// Empty bins are dropped; they carry no information about the curve
+	for _, b := range curve {
+		if b.Count == 0 {
+			continue
+		}
+		b.MeanConfidence /= float64(b.Count)
+		b.MeanOutcome /= float64(b.Count)
+		c.Curve = append(c.Curve, b)
+	}
+
+	return c
+}
@@ -0,0 +1,387 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// KeystoneMode selects how keystones are ranked
+type KeystoneMode string
+
+const (
+	KeystonePopularity  KeystoneMode = "popularity"  // (priority * 2) + (access_count / 10)
+	KeystonePageRank    KeystoneMode = "pagerank"    // Popularity blended with PageRank
+	KeystoneBetweenness KeystoneMode = "betweenness" // Popularity blended with betweenness
+)
+
+// IsValid checks if the keystone mode is valid
+func (m KeystoneMode) IsValid() bool {
+	switch m {
+	case KeystonePopularity, KeystonePageRank, KeystoneBetweenness:
+		return true
+	default:
+		return false
+	}
+}
+
+// KeystoneOptions configures GetCentralKeystones
+type KeystoneOptions struct {
+	Limit    int
+	Statuses []models.Status
+	Mode     KeystoneMode
+	Weight   *float64 // Share of the score taken by centrality, 0-1 (nil means 0.5)
+}
+
+// KeystoneScore is a ranked keystone with the parts of its score
+type KeystoneScore struct {
+	Memory      models.Memory `json:"memory"`
+	Score       float64       `json:"score"`
+	Popularity  float64       `json:"popularity"` // (priority * 2) + (access_count / 10)
+	Centrality  float64       `json:"centrality"` // Normalized to the most central memory, 0-1
+	Links       int           `json:"links"`
+	Decisions   int           `json:"decisions"`
+	Explanation string        `json:"explanation"`
+}
+
+// nodeCentrality is the cached per-memory result of a centrality run
+type nodeCentrality struct {
+	Centrality float64 `json:"centrality"`
+	Links      int     `json:"links"`
+	Decisions  int     `json:"decisions"`
+}
+
+// centralityCache is persisted between runs and only valid for one commit
+type centralityCache struct {
+	Head  string                    `json:"head"`
+	Mode  KeystoneMode              `json:"mode"`
+	Nodes map[string]nodeCentrality `json:"nodes"`
+}
+
+// GetCentralKeystones ranks memories by blending the popularity formula with
+// graph centrality over memory_links and decision co-occurrence
+func GetCentralKeystones(opts KeystoneOptions) ([]KeystoneScore, error) {
+	if opts.Mode == "" {
+		opts.Mode = KeystonePopularity
+	}
+	if !opts.Mode.IsValid() {
+		return nil, fmt.Errorf("invalid keystone mode '%s'. Must be one of: popularity, pagerank, betweenness", opts.Mode)
+	}
+	weight := 0.5
+	if opts.Weight != nil {
+		weight = *opts.Weight
+		if weight < 0 || weight > 1 {
+			return nil, fmt.Errorf("centrality weight must be between 0 and 1, got %g", weight)
+		}
+	}
+
+	where := visibleClause(opts.Statuses)
+	query := fmt.Sprintf(`
+		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, expires_at
+		FROM memories
+		WHERE %s
+	`, where)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, err
+	}
+	memories, err := parseMemoriesJSON(output)
+	if err != nil {
+		return nil, err
+	}
+	memories, err = collapseSupersession(memories, where)
+	if err != nil {
+		return nil, err
+	}
+
+	centrality := map[string]nodeCentrality{}
+	if opts.Mode != KeystonePopularity {
+		centrality, err = loadCentrality(opts.Mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	maxPopularity := 0.0
+	for _, m := range memories {
+		maxPopularity = math.Max(maxPopularity, popularity(m))
+	}
+	if maxPopularity == 0 {
+		maxPopularity = 1
+	}
+
+	scores := make([]KeystoneScore, 0, len(memories))
+	for _, m := range memories {
+		pop := popularity(m)
+		c := centrality[m.ID]
+
+		ks := KeystoneScore{
+			Memory:     m,
+			Popularity: pop,
+			Centrality: c.Centrality,
+			Links:      c.Links,
+			Decisions:  c.Decisions,
+		}
+		if opts.Mode == KeystonePopularity {
+			ks.Score = pop
+			ks.Explanation = fmt.Sprintf("popularity %.2f = priority %.2f x 2 + %d accesses / 10", pop, m.Priority, m.AccessCount)
+		} else {
+			// Both parts are scaled to 0-1 so the weight means what it says
+			ks.Score = (1-weight)*(pop/maxPopularity) + weight*c.Centrality
+			ks.Explanation = fmt.Sprintf("%.0f%% popularity %.2f (priority %.2f, %d accesses) + %.0f%% %s %.2f (%d links, %d decisions)",
+				(1-weight)*100, pop, m.Priority, m.AccessCount, weight*100, opts.Mode, c.Centrality, c.Links, c.Decisions)
+		}
+		scores = append(scores, ks)
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].Score > scores[j].Score
+	})
+	if opts.Limit > 0 && len(scores) > opts.Limit {
+		scores = scores[:opts.Limit]
+	}
+
+	return scores, nil
+}
+
+// popularity is the original keystone formula
+func popularity(m models.Memory) float64 {
+	return (m.Priority * 2) + (float64(m.AccessCount) / 10.0)
+}
+
+// loadCentrality returns cached centrality for the current commit, computing
+// and caching it when the commit has moved on
+func loadCentrality(mode KeystoneMode) (map[string]nodeCentrality, error) {
+	head, _ := db.GetHeadCommit()
+	path := centralityCachePath()
+
+	if head != "" && path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			var cached centralityCache
+			if json.Unmarshal(data, &cached) == nil && cached.Head == head && cached.Mode == mode {
+				return cached.Nodes, nil
+			}
+		}
+	}
+
+	nodes, err := computeCentrality(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	if head != "" && path != "" {
+		if data, err := json.Marshal(centralityCache{Head: head, Mode: mode, Nodes: nodes}); err == nil {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+				os.WriteFile(path, data, 0o644)
+			}
+		}
+	}
+
+	return nodes, nil
+}
+
+// centralityCachePath returns a per-repository cache file under the user
+// cache directory, or "" if there is nowhere to cache
+func centralityCachePath() string {
+	repoPath, err := db.GetRepoPath()
+	if err != nil {
+		return ""
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(repoPath))
+	return filepath.Join(cacheDir, "ami", "keystones-"+hex.EncodeToString(sum[:6])+".json")
+}
+
+// computeCentrality builds a weighted, undirected graph from memory_links and
+// decision co-occurrence and scores every memory in it
+func computeCentrality(mode KeystoneMode) (map[string]nodeCentrality, error) {
+	links, err := LoadLinks()
+	if err != nil {
+		return nil, err
+	}
+	decisions, err := ListDecisions("")
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[string]map[string]float64)
+	addEdge := func(a, b string, w float64) {
+		if a == b {
+			return
+		}
+		if weights[a] == nil {
+			weights[a] = make(map[string]float64)
+		}
+		if weights[b] == nil {
+			weights[b] = make(map[string]float64)
+		}
+		weights[a][b] += w
+		weights[b][a] += w
+	}
+
+	stats := make(map[string]nodeCentrality)
+	for _, l := range links {
+		addEdge(l.FromID, l.ToID, 1)
+		s := stats[l.FromID]
+		s.Links++
+		stats[l.FromID] = s
+		s = stats[l.ToID]
+		s.Links++
+		stats[l.ToID] = s
+	}
+	for _, d := range decisions {
+		ids := uniqueStrings(d.MemoryIDs)
+		// Spread one unit of weight across each memory's co-occurrences so a
+		// decision citing many memories doesn't dominate the graph
+		for i := range ids {
+			s := stats[ids[i]]
+			s.Decisions++
+			stats[ids[i]] = s
+			for j := i + 1; j < len(ids); j++ {
+				addEdge(ids[i], ids[j], 1/float64(len(ids)-1))
+			}
+		}
+	}
+
+	var raw map[string]float64
+	switch mode {
+	case KeystonePageRank:
+		raw = pageRank(weights, 0.85, 100, 1e-6)
+	case KeystoneBetweenness:
+		raw = betweenness(weights)
+	}
+
+	maxRaw := 0.0
+	for _, v := range raw {
+		maxRaw = math.Max(maxRaw, v)
+	}
+	for id, v := range raw {
+		s := stats[id]
+		if maxRaw > 0 {
+			s.Centrality = v / maxRaw
+		}
+		stats[id] = s
+	}
+
+	return stats, nil
+}
+
+// pageRank runs weighted PageRank by power iteration
+func pageRank(weights map[string]map[string]float64, damping float64, iterations int, tolerance float64) map[string]float64 {
+	n := float64(len(weights))
+	rank := make(map[string]float64, len(weights))
+	if n == 0 {
+		return rank
+	}
+
+	outWeight := make(map[string]float64, len(weights))
+	for id, nbrs := range weights {
+		rank[id] = 1 / n
+		for _, w := range nbrs {
+			outWeight[id] += w
+		}
+	}
+
+	for iter := 0; iter < iterations; iter++ {
+		next := make(map[string]float64, len(weights))
+		for id := range weights {
+			next[id] = (1 - damping) / n
+		}
+		for id, nbrs := range weights {
+			if outWeight[id] == 0 {
+				continue
+			}
+			for nbr, w := range nbrs {
+				next[nbr] += damping * rank[id] * w / outWeight[id]
+			}
+		}
+
+		delta := 0.0
+		for id := range weights {
+			delta += math.Abs(next[id] - rank[id])
+		}
+		rank = next
+		if delta < tolerance {
+			break
+		}
+	}
+
+	return rank
+}
+
+// betweenness computes unweighted betweenness centrality with Brandes'
+// algorithm; any edge counts as one hop regardless of weight
+func betweenness(weights map[string]map[string]float64) map[string]float64 {
+	ids := make([]string, 0, len(weights))
+	for id := range weights {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	cb := make(map[string]float64, len(ids))
+	for _, s := range ids {
+		var stack []string
+		pred := make(map[string][]string)
+		sigma := map[string]float64{s: 1}
+		dist := map[string]int{s: 0}
+		queue := []string{s}
+
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+			for w := range weights[v] {
+				if _, seen := dist[w]; !seen {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					pred[w] = append(pred[w], v)
+				}
+			}
+		}
+
+		delta := make(map[string]float64)
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range pred[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				cb[w] += delta[w]
+			}
+		}
+	}
+
+	// Each undirected path was counted from both ends
+	for id := range cb {
+		cb[id] /= 2
+	}
+	return cb
+}
+
+// uniqueStrings drops repeated values, keeping the first occurrence
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,274 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// Checkpoint is a named Dolt tag marking a safe point in the memory history
+type Checkpoint struct {
+	Name        string    `json:"name"`
+	Hash        string    `json:"hash"`
+	Description string    `json:"description"`
+	Owner       string    `json:"owner"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CheckpointDiff is a single memory row that changed since a checkpoint
+type CheckpointDiff struct {
+	DiffType    string `json:"diff_type"` // "added", "modified" or "removed"
+	ID          string `json:"id"`
+	FromContent string `json:"from_content,omitempty"`
+	ToContent   string `json:"to_content,omitempty"`
+}
+
+// CreateCheckpoint commits any pending changes and tags HEAD. The owner and
+// timestamp are recorded as trailers in the tag message.
+func CreateCheckpoint(name, description, owner string) (*Checkpoint, error) {
+	now := time.Now()
+	if name == "" {
+		// Timestamps collide within a second, so number repeats
+		base := "checkpoint-" + now.Format("20060102-150405")
+		name = base
+		for i := 2; ; i++ {
+			exists, err := checkpointExists(name)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				break
+			}
+			name = fmt.Sprintf("%s-%d", base, i)
+		}
+	} else if exists, err := checkpointExists(name); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, fmt.Errorf("checkpoint %q already exists", name)
+	}
+	if description == "" {
+		description = "Checkpoint"
+	}
+	if owner == "" {
+		owner = "system"
+	}
+
+	if err := DoltCommit(fmt.Sprintf("Checkpoint: %s", description)); err != nil {
+		return nil, fmt.Errorf("failed to commit pending changes: %w", err)
+	}
+
+	message := fmt.Sprintf("%s\n\nOwner: %s\nCreated-At: %s", description, owner, now.Format(time.RFC3339))
+	if err := db.DoltTag(name, message); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint tag: %w", err)
+	}
+
+	hash, _ := db.GetHeadCommit()
+	return &Checkpoint{
+		Name:        name,
+		Hash:        hash,
+		Description: description,
+		Owner:       owner,
+		CreatedAt:   now,
+	}, nil
+}
+
+// ListCheckpoints returns all tags, newest first
+func ListCheckpoints() ([]Checkpoint, error) {
+	output, err := ExecDoltSQLJSON("SELECT tag_name, tag_hash, tagger, date, message FROM dolt_tags ORDER BY date DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	checkpoints := make([]Checkpoint, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		cp := parseCheckpointMessage(models.AsString(row["message"]))
+		cp.Name = models.AsString(row["tag_name"])
+		cp.Hash = models.AsString(row["tag_hash"])
+		if cp.Owner == "" {
+			cp.Owner = models.AsString(row["tagger"])
+		}
+		if cp.CreatedAt.IsZero() {
+			cp.CreatedAt = models.AsTime(row["date"])
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+
+	return checkpoints, nil
+}
+
+// DiffCheckpoint lists memories that were added, modified or removed between
+// the checkpoint and HEAD
+func DiffCheckpoint(name string) ([]CheckpointDiff, error) {
+	query := fmt.Sprintf(`
+		SELECT diff_type, from_id, to_id, from_content, to_content
+		FROM dolt_diff('%s', 'HEAD', 'memories')
+	`, strings.ReplaceAll(name, "'", "''"))
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff checkpoint %s: %w", name, err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	diffs := make([]CheckpointDiff, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		d := CheckpointDiff{
+			DiffType:    models.AsString(row["diff_type"]),
+			ID:          models.AsString(row["to_id"]),
+			FromContent: models.AsString(row["from_content"]),
+			ToContent:   models.AsString(row["to_content"]),
+		}
+		if d.ID == "" {
+			d.ID = models.AsString(row["from_id"])
+		}
+		diffs = append(diffs, d)
+	}
+
+	return diffs, nil
+}
+
+// RestoreCheckpoint rewrites every table to its state at the checkpoint and
+// records the result as a new commit, so history is kept. Tables created
+// since the checkpoint (credits, aliases, conflicts...) are emptied, as
+// their rows refer to memories and decisions the restore rolls back. A
+// checkpoint taken before a schema change is refused rather than partly
+// restored.
+func RestoreCheckpoint(name string) error {
+	// Don't let uncommitted work get silently mixed into the restore
+	if err := DoltCommit(fmt.Sprintf("Auto-commit before restoring checkpoint %s", name)); err != nil {
+		return fmt.Errorf("failed to commit pending changes: %w", err)
+	}
+
+	current, err := listTables("")
+	if err != nil {
+		return err
+	}
+	atCheckpoint, err := listTables(name)
+	if err != nil {
+		return err
+	}
+	existed := make(map[string]bool, len(atCheckpoint))
+	for _, t := range atCheckpoint {
+		existed[t] = true
+	}
+
+	var shared []string
+	var script strings.Builder
+	for _, t := range current {
+		if existed[t] {
+			shared = append(shared, t)
+		} else {
+			fmt.Fprintf(&script, "DELETE FROM `%s`;\n", t)
+		}
+	}
+
+	if len(shared) > 0 {
+		// A data diff skips rows across schema changes, so restoring over a
+		// migration would silently leave data behind
+		args := append([]string{"diff", "--schema", "-r", "sql", "HEAD", name}, shared...)
+		schemaPatch, err := db.RunDolt(args...)
+		if err != nil {
+			return fmt.Errorf("failed to compare schema with checkpoint: %w", err)
+		}
+		if strings.TrimSpace(schemaPatch) != "" {
+			return fmt.Errorf("the schema changed since checkpoint %s; it can't be restored in place (dolt reset --hard %s rolls back everything)", name, name)
+		}
+
+		args = append([]string{"diff", "--data", "-r", "sql", "HEAD", name}, shared...)
+		patch, err := db.RunDolt(args...)
+		if err != nil {
+			return fmt.Errorf("failed to compute restore patch: %w", err)
+		}
+		script.WriteString(patch)
+	}
+
+	if strings.TrimSpace(script.String()) == "" {
+		return nil
+	}
+
+	// Links and memories reference each other, so apply the patch unchecked
+	patch := "SET FOREIGN_KEY_CHECKS = 0;\n" + script.String() + "\nSET FOREIGN_KEY_CHECKS = 1;\n"
+	if err := db.ExecDoltSQLScript(patch); err != nil {
+		return fmt.Errorf("failed to apply restore patch: %w", err)
+	}
+
+	return DoltCommit(fmt.Sprintf("Restore checkpoint %s", name))
+}
+
+// checkpointExists reports whether a tag with this name exists
+func checkpointExists(name string) (bool, error) {
+	output, err := ExecDoltSQLJSON(fmt.Sprintf("SELECT tag_name FROM dolt_tags WHERE tag_name = '%s'", strings.ReplaceAll(name, "'", "''")))
+	if err != nil {
+		return false, fmt.Errorf("failed to check checkpoint %s: %w", name, err)
+	}
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return false, err
+	}
+	return len(result.Rows) > 0, nil
+}
+
+// listTables returns the user tables at a revision, or in the working set
+// when rev is empty
+func listTables(rev string) ([]string, error) {
+	query := "SHOW TABLES"
+	if rev != "" {
+		query += fmt.Sprintf(" AS OF '%s'", strings.ReplaceAll(rev, "'", "''"))
+	}
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, err
+	}
+
+	// The single column is named after the database (Tables_in_<db>)
+	tables := make([]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		for _, v := range row {
+			tables = append(tables, models.AsString(v))
+		}
+	}
+	return tables, nil
+}
+
+// parseCheckpointMessage splits a tag message into description and trailers
+func parseCheckpointMessage(message string) Checkpoint {
+	var cp Checkpoint
+	var desc []string
+	for _, line := range strings.Split(message, "\n") {
+		switch {
+		case strings.HasPrefix(line, "Owner: "):
+			cp.Owner = strings.TrimPrefix(line, "Owner: ")
+		case strings.HasPrefix(line, "Created-At: "):
+			cp.CreatedAt, _ = time.Parse(time.RFC3339, strings.TrimPrefix(line, "Created-At: "))
+		default:
+			desc = append(desc, line)
+		}
+	}
+	cp.Description = strings.TrimSpace(strings.Join(desc, "\n"))
+	return cp
+}
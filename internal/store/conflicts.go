@@ -0,0 +1,492 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// ConflictStatus represents the lifecycle state of a detected conflict
+type ConflictStatus string
+
+const (
+	ConflictOpen      ConflictStatus = "open"
+	ConflictResolved  ConflictStatus = "resolved"
+	ConflictDismissed ConflictStatus = "dismissed"
+)
+
+// IsValid checks if the conflict status is valid
+func (s ConflictStatus) IsValid() bool {
+	switch s {
+	case ConflictOpen, ConflictResolved, ConflictDismissed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Conflict is a pair of memories flagged as possibly contradicting each other
+type Conflict struct {
+	ID         string         `json:"id"`
+	MemoryA    string         `json:"memory_a"`
+	MemoryB    string         `json:"memory_b"`
+	TeamID     string         `json:"team_id"`
+	Similarity float64        `json:"similarity"`
+	Method     string         `json:"method"`
+	Reason     string         `json:"reason,omitempty"`
+	Status     ConflictStatus `json:"status"`
+	Resolution string         `json:"resolution,omitempty"`
+	DetectedAt time.Time      `json:"detected_at"`
+	ResolvedAt time.Time      `json:"resolved_at"`
+}
+
+// ResolveCommand returns the CLI invocation that resolves this conflict
+func (c Conflict) ResolveCommand() string {
+	return fmt.Sprintf("ami conflict resolve %s %s", c.MemoryA, c.MemoryB)
+}
+
+// ConflictCheckFailure is a candidate pair the LLM could not judge. It is not
+// recorded, so the next detect run checks it again.
+type ConflictCheckFailure struct {
+	MemoryA string `json:"memory_a"`
+	MemoryB string `json:"memory_b"`
+	Error   string `json:"error"`
+}
+
+// ConflictDetectOptions controls candidate search in DetectConflicts
+type ConflictDetectOptions struct {
+	TeamID           string
+	Threshold        float64 // Minimum cosine similarity between embeddings
+	LexicalThreshold float64 // Minimum term overlap when embeddings are missing
+	Limit            int     // Maximum number of memories scanned
+	Ollama           *db.OllamaClient
+}
+
+// DetectConflicts finds highly similar pairs of verified memories within the
+// same team and records them in the conflicts table. When an Ollama client is
+// supplied, each candidate is only recorded if the model says it contradicts;
+// pairs the model fails on are returned as failures without stopping the run.
+func DetectConflicts(ctx context.Context, opts ConflictDetectOptions) ([]Conflict, []ConflictCheckFailure, error) {
+	whereClauses := []string{"COALESCE(status, 'verified') = 'verified'"}
+	if opts.TeamID != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("team_id = '%s'", strings.ReplaceAll(opts.TeamID, "'", "''")))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, embedding, status, team_id
+		FROM memories
+		WHERE %s
+		ORDER BY accessed_at DESC
+		LIMIT %d
+	`, strings.Join(whereClauses, " AND "), opts.Limit)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load memories: %w", err)
+	}
+
+	memories, err := parseMemoriesJSON(output)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	known, err := knownConflictPairs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Only compare memories that share a team
+	byTeam := make(map[string][]models.Memory)
+	for _, m := range memories {
+		byTeam[m.TeamID] = append(byTeam[m.TeamID], m)
+	}
+
+	var found []Conflict
+	var failures []ConflictCheckFailure
+	now := time.Now()
+	for teamID, group := range byTeam {
+		for i := 0; i < len(group); i++ {
+			for j := i + 1; j < len(group); j++ {
+				a, b := group[i], group[j]
+				if a.ID > b.ID {
+					a, b = b, a
+				}
+				if known[a.ID+"|"+b.ID] {
+					continue
+				}
+				// Identical facts are duplicates, not contradictions
+				if ContentHash(a.Content) == ContentHash(b.Content) {
+					continue
+				}
+
+				score, method := MemorySimilarity(a, b)
+				threshold := opts.Threshold
+				if method == "lexical" {
+					threshold = opts.LexicalThreshold
+				}
+				if score < threshold {
+					continue
+				}
+
+				reason := "high similarity"
+				if opts.Ollama != nil {
+					contradicts, why, err := AskContradiction(ctx, opts.Ollama, a.Content, b.Content)
+					if err != nil {
+						failures = append(failures, ConflictCheckFailure{MemoryA: a.ID, MemoryB: b.ID, Error: err.Error()})
+						continue
+					}
+					if !contradicts {
+						continue
+					}
+					reason = why
+				}
+
+				found = append(found, Conflict{
+					ID:         uuid.New().String(),
+					MemoryA:    a.ID,
+					MemoryB:    b.ID,
+					TeamID:     teamID,
+					Similarity: score,
+					Method:     method,
+					Reason:     reason,
+					Status:     ConflictOpen,
+					DetectedAt: now,
+				})
+			}
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Similarity > found[j].Similarity
+	})
+
+	if len(found) == 0 {
+		return found, failures, nil
+	}
+
+	detectedAt := now.Format("2006-01-02 15:04:05")
+	for _, c := range found {
+		insert := fmt.Sprintf(`
+			INSERT INTO conflicts (id, memory_a, memory_b, team_id, similarity, method, reason, status, detected_at)
+			VALUES ('%s', '%s', '%s', '%s', %f, '%s', '%s', '%s', '%s')
+		`, c.ID, c.MemoryA, c.MemoryB, strings.ReplaceAll(c.TeamID, "'", "''"), c.Similarity, c.Method,
+			strings.ReplaceAll(c.Reason, "'", "''"), string(c.Status), detectedAt)
+		if _, err := db.ExecDoltSQL(insert); err != nil {
+			return nil, failures, fmt.Errorf("failed to record conflict: %w", err)
+		}
+	}
+
+	if err := DoltCommit(fmt.Sprintf("Detect %d memory conflict(s)", len(found))); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+
+	return found, failures, nil
+}
+
+// AskContradiction asks the local LLM whether two statements contradict
+func AskContradiction(ctx context.Context, ollama *db.OllamaClient, a, b string) (bool, string, error) {
+	prompt := fmt.Sprintf(`
+You are checking a team knowledge base for contradictions.
+Do the following two statements contradict each other (they cannot both be true at the same time)?
+Answer with YES or NO on the first line, then one short sentence explaining why.
+
+Statement A: %s
+Statement B: %s
+Answer:`, a, b)
+
+	response, err := ollama.Generate(ctx, prompt)
+	if err != nil {
+		return false, "", err
+	}
+
+	response = strings.TrimSpace(response)
+	firstLine, rest, _ := strings.Cut(response, "\n")
+	verdict := strings.ToUpper(strings.TrimSpace(firstLine))
+	reason := strings.TrimSpace(rest)
+	if reason == "" {
+		// The model may answer "YES - because ..." on a single line
+		reason = strings.TrimSpace(firstLine)
+	}
+
+	return strings.HasPrefix(verdict, "YES"), reason, nil
+}
+
+// knownConflictPairs returns every pair already recorded, in any status, so
+// dismissed or resolved pairs are not flagged again
+func knownConflictPairs() (map[string]bool, error) {
+	output, err := ExecDoltSQLJSON("SELECT memory_a, memory_b FROM conflicts")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing conflicts: %w", err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(result.Rows))
+	for _, row := range result.Rows {
+		known[models.AsString(row["memory_a"])+"|"+models.AsString(row["memory_b"])] = true
+	}
+	return known, nil
+}
+
+// ListConflicts returns recorded conflicts, optionally filtered by status and team
+func ListConflicts(status ConflictStatus, teamID string) ([]Conflict, error) {
+	whereClauses := []string{}
+	if status != "" {
+		if !status.IsValid() {
+			return nil, fmt.Errorf("invalid status: %s (must be open, resolved or dismissed)", status)
+		}
+		whereClauses = append(whereClauses, fmt.Sprintf("status = '%s'", string(status)))
+	}
+	if teamID != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("team_id = '%s'", strings.ReplaceAll(teamID, "'", "''")))
+	}
+
+	whereClause := ""
+	if len(whereClauses) > 0 {
+		whereClause = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, memory_a, memory_b, team_id, similarity, method, reason, status, resolution, detected_at, resolved_at
+		FROM conflicts
+		%s
+		ORDER BY similarity DESC, detected_at DESC
+	`, whereClause)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicts: %w", err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	conflicts := make([]Conflict, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		conflicts = append(conflicts, Conflict{
+			ID:         models.AsString(row["id"]),
+			MemoryA:    models.AsString(row["memory_a"]),
+			MemoryB:    models.AsString(row["memory_b"]),
+			TeamID:     models.AsString(row["team_id"]),
+			Similarity: models.AsFloat64(row["similarity"]),
+			Method:     models.AsString(row["method"]),
+			Reason:     models.AsString(row["reason"]),
+			Status:     ConflictStatus(models.AsString(row["status"])),
+			Resolution: models.AsString(row["resolution"]),
+			DetectedAt: models.AsTime(row["detected_at"]),
+			ResolvedAt: models.AsTime(row["resolved_at"]),
+		})
+	}
+
+	return conflicts, nil
+}
+
+// ResolutionStrategy selects how ResolveConflict settles a pair of memories
+type ResolutionStrategy string
+
+const (
+	StrategyKeepFirst  ResolutionStrategy = "keep-first"
+	StrategyKeepSecond ResolutionStrategy = "keep-second"
+	StrategyMerge      ResolutionStrategy = "merge"
+	StrategyLLMMerge   ResolutionStrategy = "llm-merge"
+	StrategyKeepBoth   ResolutionStrategy = "keep-both"
+)
+
+// IsValid checks if the resolution strategy is valid
+func (s ResolutionStrategy) IsValid() bool {
+	switch s {
+	case StrategyKeepFirst, StrategyKeepSecond, StrategyMerge, StrategyLLMMerge, StrategyKeepBoth:
+		return true
+	default:
+		return false
+	}
+}
+
+// ConflictResolution reports the outcome of ResolveConflict
+type ConflictResolution struct {
+	Strategy ResolutionStrategy `json:"strategy"`
+	WinnerID string             `json:"winner_id,omitempty"`
+	LoserID  string             `json:"loser_id,omitempty"`
+	Content  string             `json:"content,omitempty"` // Winner's content after resolution
+}
+
+// ResolveConflict settles a conflict between two memories in a single Dolt
+// commit. The losing memory is deprecated and the winner gets a supersedes
+// link to it; merge strategies rewrite the winner's content first.
+func ResolveConflict(ctx context.Context, id1, id2 string, strategy ResolutionStrategy, ollama *db.OllamaClient) (*ConflictResolution, error) {
+	if !strategy.IsValid() {
+		return nil, fmt.Errorf("invalid strategy: %s", strategy)
+	}
+	if id1 == id2 {
+		return nil, fmt.Errorf("cannot resolve memory %s against itself", id1)
+	}
+
+	m1, err := GetMemoryByID(id1)
+	if err != nil {
+		return nil, err
+	}
+	m2, err := GetMemoryByID(id2)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ConflictResolution{Strategy: strategy}
+	winner, loser := m1, m2
+
+	switch strategy {
+	case StrategyKeepBoth:
+		if err := markConflict(id1, id2, ConflictDismissed, strategy); err != nil {
+			return nil, err
+		}
+		if err := DoltCommit(fmt.Sprintf("Dismiss conflict between %s and %s", id1, id2)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+		}
+		return res, nil
+	case StrategyKeepSecond:
+		winner, loser = m2, m1
+	case StrategyMerge:
+		res.Content = mergeStatements(m1.Content, m2.Content)
+	case StrategyLLMMerge:
+		if ollama == nil {
+			return nil, fmt.Errorf("llm-merge requires an Ollama client")
+		}
+		merged, err := MergeFactsWithLLM(ctx, ollama, m1.Content, m2.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge with LLM: %w", err)
+		}
+		res.Content = merged
+	}
+
+	res.WinnerID = winner.ID
+	res.LoserID = loser.ID
+
+	if res.Content != "" {
+		priority := winner.Priority
+		if loser.Priority > priority {
+			priority = loser.Priority
+		}
+		update := fmt.Sprintf(`
+			UPDATE memories
+			SET content = '%s', content_hash = '%s', priority = %f, accessed_at = NOW()
+			WHERE id = '%s'
+		`, strings.ReplaceAll(res.Content, "'", "''"), ContentHash(res.Content), priority, winner.ID)
+		if _, err := db.ExecDoltSQL(update); err != nil {
+			return nil, fmt.Errorf("failed to update memory %s: %w", winner.ID, err)
+		}
+	} else {
+		res.Content = winner.Content
+	}
+
+	deprecate := fmt.Sprintf("UPDATE memories SET status = '%s' WHERE id = '%s'", models.StatusDeprecated, loser.ID)
+	if _, err := db.ExecDoltSQL(deprecate); err != nil {
+		return nil, fmt.Errorf("failed to deprecate memory %s: %w", loser.ID, err)
+	}
+
+	if err := insertLink(winner.ID, loser.ID, "supersedes"); err != nil {
+		return nil, fmt.Errorf("failed to link memories: %w", err)
+	}
+
+	if err := markConflict(id1, id2, ConflictResolved, strategy); err != nil {
+		return nil, err
+	}
+
+	commitMsg := fmt.Sprintf("Resolve conflict (%s): %s supersedes %s", strategy, winner.ID, loser.ID)
+	if err := DoltCommit(commitMsg); err != nil {
+		return nil, fmt.Errorf("failed to commit resolution: %w", err)
+	}
+
+	return res, nil
+}
+
+// MergeFactsWithLLM asks the local LLM to rewrite two overlapping facts as one
+func MergeFactsWithLLM(ctx context.Context, ollama *db.OllamaClient, a, b string) (string, error) {
+	prompt := fmt.Sprintf(`
+Two entries in a team knowledge base overlap or disagree.
+Rewrite them as a single, coherent, self-contained fact. Keep every detail that is still true,
+prefer the more specific statement when they disagree, and do not add new information.
+Reply with the fact only, on one line.
+
+Fact A: %s
+Fact B: %s
+Merged fact:`, a, b)
+
+	response, err := ollama.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	merged := strings.TrimSpace(response)
+	merged = strings.TrimPrefix(merged, "- ")
+	merged = strings.Trim(merged, "\"")
+	if merged == "" {
+		return "", fmt.Errorf("model returned an empty fact")
+	}
+	return merged, nil
+}
+
+// sentenceBoundary splits text into sentences: terminal punctuation followed
+// by whitespace or the end of the text, or a line break. Dots inside
+// versions, hostnames and decimals ("v1.2.3", "example.com", "0.5s") stay.
+var sentenceBoundary = regexp.MustCompile(`[.!?]+(?:\s+|$)|\n+`)
+
+// mergeStatements combines two facts without an LLM, dropping sentences that
+// are already present in the other fact
+func mergeStatements(a, b string) string {
+	if strings.Contains(NormalizeContent(a), NormalizeContent(b)) {
+		return a
+	}
+	if strings.Contains(NormalizeContent(b), NormalizeContent(a)) {
+		return b
+	}
+
+	seen := make(map[string]bool)
+	var parts []string
+	for _, text := range []string{a, b} {
+		for _, sentence := range sentenceBoundary.Split(text, -1) {
+			sentence = strings.TrimSpace(sentence)
+			key := NormalizeContent(sentence)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			parts = append(parts, sentence)
+		}
+	}
+	return strings.Join(parts, ". ") + "."
+}
+
+// markConflict closes the recorded conflict for a pair, if one exists
+func markConflict(id1, id2 string, status ConflictStatus, strategy ResolutionStrategy) error {
+	a, b := id1, id2
+	if a > b {
+		a, b = b, a
+	}
+	query := fmt.Sprintf(`
+		UPDATE conflicts
+		SET status = '%s', resolution = '%s', resolved_at = NOW()
+		WHERE memory_a = '%s' AND memory_b = '%s'
+	`, string(status), string(strategy), a, b)
+
+	if _, err := db.ExecDoltSQL(query); err != nil {
+		return fmt.Errorf("failed to update conflict record: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// ConsolidateOptions controls which episodic memories are clustered and how
+type ConsolidateOptions struct {
+	Hours            int
+	Limit            int
+	TeamID           string
+	Threshold        float64 // Minimum cosine similarity to join a cluster
+	LexicalThreshold float64 // Minimum term overlap when embeddings are missing
+	MinClusterSize   int
+	Demote           bool // Halve the priority of consolidated sources
+	DryRun           bool
+	Ollama           *db.OllamaClient
+}
+
+// ConsolidationCluster is a group of episodic memories and the semantic fact
+// synthesized from them
+type ConsolidationCluster struct {
+	TeamID  string          `json:"team_id"`
+	Sources []models.Memory `json:"sources"`
+	Fact    string          `json:"fact"`
+	FactID  string          `json:"fact_id,omitempty"`
+	Action  string          `json:"action,omitempty"` // AddResult action for the staged fact
+	Method  string          `json:"method"`
+}
+
+// ConsolidateMemories clusters recent episodic memories and stages one
+// under_review semantic fact per cluster, linked derived_from to its sources.
+// Everything is written in a single Dolt commit; DryRun writes nothing.
+func ConsolidateMemories(ctx context.Context, opts ConsolidateOptions) ([]ConsolidationCluster, error) {
+	if opts.Ollama == nil {
+		return nil, fmt.Errorf("consolidation requires an Ollama client")
+	}
+
+	memories, err := loadConsolidationCandidates(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := ClusterMemories(memories, opts.Threshold, opts.LexicalThreshold)
+
+	var clusters []ConsolidationCluster
+	for _, group := range groups {
+		if len(group) < opts.MinClusterSize {
+			continue
+		}
+
+		contents := make([]string, 0, len(group))
+		for _, m := range group {
+			contents = append(contents, m.Content)
+		}
+
+		fact, err := SynthesizeFact(ctx, opts.Ollama, contents)
+		if err != nil {
+			return nil, fmt.Errorf("failed to synthesize fact: %w", err)
+		}
+
+		method := "lexical"
+		if len(group[0].Embedding) > 0 {
+			method = "embedding"
+		}
+
+		clusters = append(clusters, ConsolidationCluster{
+			TeamID:  group[0].TeamID,
+			Sources: group,
+			Fact:    fact,
+			Method:  method,
+		})
+	}
+
+	if opts.DryRun || len(clusters) == 0 {
+		return clusters, nil
+	}
+
+	err = writeOrRollBack(func() error {
+		for i := range clusters {
+			if err := stageCluster(&clusters[i], opts.Demote); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	commitMsg := fmt.Sprintf("Consolidate episodic memories into %d semantic fact(s)", len(clusters))
+	if err := DoltCommit(commitMsg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+
+	return clusters, nil
+}
+
+// stageCluster writes a cluster's fact as a new under_review memory linked
+// derived_from to its sources, without committing
+func stageCluster(c *ConsolidationCluster, demoteSources bool) error {
+	priority := 0.0
+	tagSet := map[string]bool{"consolidated": true}
+	sourceIDs := make([]string, 0, len(c.Sources))
+	for _, m := range c.Sources {
+		if m.Priority > priority {
+			priority = m.Priority
+		}
+		for _, t := range m.Tags {
+			tagSet[t] = true
+		}
+		sourceIDs = append(sourceIDs, m.ID)
+	}
+	tags := make([]string, 0, len(tagSet))
+	for t := range tagSet {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+
+	// A fact restates its sources, so they never count as duplicates; any
+	// other match is linked, keeping the fact in the review queue
+	added, err := insertMemory(AddParams{
+		Content:     c.Fact,
+		OwnerID:     "system",
+		Category:    models.CategorySemantic,
+		Priority:    priority,
+		Tags:        tags,
+		Source:      "consolidate",
+		TeamID:      c.TeamID,
+		Status:      models.StatusUnderReview,
+		OnDuplicate: DuplicateLink,
+		Search:      DuplicateSearch{ExcludeIDs: sourceIDs},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stage fact: %w", err)
+	}
+	c.FactID = added.Memory.ID
+	c.Action = added.Action
+
+	for _, m := range c.Sources {
+		if m.ID == c.FactID {
+			continue
+		}
+		if err := insertLink(c.FactID, m.ID, "derived_from"); err != nil {
+			return fmt.Errorf("failed to link %s to %s: %w", c.FactID, m.ID, err)
+		}
+		if demoteSources {
+			demote := fmt.Sprintf("UPDATE memories SET priority = priority * 0.5 WHERE id = '%s'", m.ID)
+			if _, err := db.ExecDoltSQL(demote); err != nil {
+				return fmt.Errorf("failed to demote memory %s: %w", m.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// loadConsolidationCandidates returns recent, non-deprecated episodic
+// memories that have not already been consolidated into a fact
+func loadConsolidationCandidates(opts ConsolidateOptions) ([]models.Memory, error) {
+	since := time.Now().Add(-time.Duration(opts.Hours) * time.Hour).Format("2006-01-02 15:04:05")
+
+	whereClauses := []string{
+		"category = 'episodic'",
+		"COALESCE(status, 'verified') != 'deprecated'",
+		fmt.Sprintf("created_at >= '%s'", since),
+		"id NOT IN (SELECT to_id FROM memory_links WHERE relation = 'derived_from')",
+	}
+	if opts.TeamID != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("team_id = '%s'", strings.ReplaceAll(opts.TeamID, "'", "''")))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, embedding, status, team_id
+		FROM memories
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT %d
+	`, strings.Join(whereClauses, " AND "), opts.Limit)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load episodic memories: %w", err)
+	}
+
+	return parseMemoriesJSON(output)
+}
+
+// ClusterMemories groups memories by single-linkage clustering: two memories
+// in the same team end up together if a chain of pairs above the threshold
+// connects them. Clusters are returned largest first.
+func ClusterMemories(memories []models.Memory, threshold, lexicalThreshold float64) [][]models.Memory {
+	parent := make([]int, len(memories))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	for i := 0; i < len(memories); i++ {
+		for j := i + 1; j < len(memories); j++ {
+			if memories[i].TeamID != memories[j].TeamID {
+				continue
+			}
+			score, method := MemorySimilarity(memories[i], memories[j])
+			limit := threshold
+			if method == "lexical" {
+				limit = lexicalThreshold
+			}
+			if score >= limit {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	byRoot := make(map[int][]models.Memory)
+	var roots []int
+	for i, m := range memories {
+		r := find(i)
+		if _, ok := byRoot[r]; !ok {
+			roots = append(roots, r)
+		}
+		byRoot[r] = append(byRoot[r], m)
+	}
+
+	clusters := make([][]models.Memory, 0, len(roots))
+	for _, r := range roots {
+		clusters = append(clusters, byRoot[r])
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i]) > len(clusters[j])
+	})
+
+	return clusters
+}
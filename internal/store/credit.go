@@ -0,0 +1,246 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// Credit assignment: each outcome is split across the memories that informed
+// the decision. A memory's share w = 1/n adds w*outcome to its successes and
+// w*(1-outcome) to its failures, and its confidence is the mean of a Beta
+// posterior over those counts. Credits are stored per (memory, decision), so
+// re-recording an outcome replaces the earlier credit instead of stacking.
+
+const (
+	// creditPriorAlpha and creditPriorBeta are the Beta(1, 1) uniform prior
+	creditPriorAlpha = 1.0
+	creditPriorBeta  = 1.0
+
+	// creditPriorityStep is the largest priority change one outcome can
+	// cause, for a memory that informed a decision alone
+	creditPriorityStep = 0.2
+)
+
+// Credit is one decision's contribution to a memory's track record
+type Credit struct {
+	MemoryID      string    `json:"memory_id"`
+	DecisionID    string    `json:"decision_id"`
+	Weight        float64   `json:"weight"`
+	Outcome       float64   `json:"outcome"`
+	Successes     float64   `json:"successes"`
+	Failures      float64   `json:"failures"`
+	PriorityDelta float64   `json:"priority_delta"`
+	RecordedAt    time.Time `json:"recorded_at"`
+}
+
+// TrackRecord summarizes how decisions informed by a memory turned out
+type TrackRecord struct {
+	MemoryID   string   `json:"memory_id"`
+	Decisions  int      `json:"decisions"`
+	Successes  float64  `json:"successes"`
+	Failures   float64  `json:"failures"`
+	Confidence float64  `json:"confidence"` // Posterior mean success rate, 0.5 with no evidence
+	Credits    []Credit `json:"credits,omitempty"`
+}
+
+// creditConfidence is the Beta posterior mean for the given counts
+func creditConfidence(successes, failures float64) float64 {
+	return (creditPriorAlpha + successes) / (creditPriorAlpha + creditPriorBeta + successes + failures)
+}
+
+// assignCredit returns the credit each memory earns from an outcome
+func assignCredit(decisionID string, memoryIDs []string, outcome float64) []Credit {
+	ids := uniqueStrings(memoryIDs)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	w := 1 / float64(len(ids))
+	credits := make([]Credit, 0, len(ids))
+	for _, id := range ids {
+		credits = append(credits, Credit{
+			MemoryID:   id,
+			DecisionID: decisionID,
+			Weight:     w,
+			Outcome:    outcome,
+			Successes:  w * outcome,
+			Failures:   w * (1 - outcome),
+			// Centered on 0.5 so failures lower priority as much as successes raise it
+			PriorityDelta: w * (outcome - 0.5) * creditPriorityStep,
+		})
+	}
+	return credits
+}
+
+// loadCredits returns credits matching where
+func loadCredits(where string) ([]Credit, error) {
+	query := "SELECT memory_id, decision_id, weight, outcome, successes, failures, priority_delta, recorded_at FROM memory_credit"
+	if where != "" {
+		query += " WHERE " + where
+	}
+	query += " ORDER BY recorded_at DESC"
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load credits: %w", err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, err
+	}
+
+	credits := make([]Credit, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		credits = append(credits, Credit{
+			MemoryID:      models.AsString(row["memory_id"]),
+			DecisionID:    models.AsString(row["decision_id"]),
+			Weight:        models.AsFloat64(row["weight"]),
+			Outcome:       models.AsFloat64(row["outcome"]),
+			Successes:     models.AsFloat64(row["successes"]),
+			Failures:      models.AsFloat64(row["failures"]),
+			PriorityDelta: models.AsFloat64(row["priority_delta"]),
+			RecordedAt:    models.AsTime(row["recorded_at"]),
+		})
+	}
+	return credits, nil
+}
+
+// GetTrackRecord returns a memory's outcome history and confidence
+func GetTrackRecord(memoryID string) (*TrackRecord, error) {
+	credits, err := loadCredits(fmt.Sprintf("memory_id = '%s'", strings.ReplaceAll(memoryID, "'", "''")))
+	if err != nil {
+		return nil, err
+	}
+
+	record := summarizeCredits(memoryID, credits)
+	record.Credits = credits
+	return &record, nil
+}
+
+// LoadTrackRecords returns the track record of every credited memory, without
+// the individual credits
+func LoadTrackRecords() (map[string]TrackRecord, error) {
+	credits, err := loadCredits("")
+	if err != nil {
+		return nil, err
+	}
+
+	byMemory := make(map[string][]Credit)
+	for _, c := range credits {
+		byMemory[c.MemoryID] = append(byMemory[c.MemoryID], c)
+	}
+
+	records := make(map[string]TrackRecord, len(byMemory))
+	for id, cs := range byMemory {
+		records[id] = summarizeCredits(id, cs)
+	}
+	return records, nil
+}
+
+// summarizeCredits totals credits into a track record
+func summarizeCredits(memoryID string, credits []Credit) TrackRecord {
+	record := TrackRecord{MemoryID: memoryID, Decisions: len(credits)}
+	for _, c := range credits {
+		record.Successes += c.Successes
+		record.Failures += c.Failures
+	}
+	record.Confidence = creditConfidence(record.Successes, record.Failures)
+	return record
+}
+
+// creditScript writes the credits for a decision, first backing out any
+// priority change its previous outcome made. Priority is clamped to 0-1 in Go
+// so each credit stores the change that was actually applied, and backing it
+// out later restores the priority exactly.
+func creditScript(decisionID string, credits []Credit) (string, error) {
+	previous, err := loadCredits(fmt.Sprintf("decision_id = '%s'", decisionID))
+	if err != nil {
+		return "", err
+	}
+	oldDelta := make(map[string]float64, len(previous))
+	credited := make(map[string]bool, len(previous))
+	ids := make([]string, 0, len(previous)+len(credits))
+	for _, c := range previous {
+		oldDelta[c.MemoryID] = c.PriorityDelta
+		credited[c.MemoryID] = true
+		ids = append(ids, c.MemoryID)
+	}
+	for _, c := range credits {
+		ids = append(ids, c.MemoryID)
+	}
+	priorities, err := loadPriorities(ids)
+	if err != nil {
+		return "", err
+	}
+
+	var script strings.Builder
+	// Memories dropped from the decision since the last outcome lose their credit
+	fmt.Fprintf(&script, "DELETE FROM memory_credit WHERE decision_id = '%s';\n", decisionID)
+
+	now := time.Now().Format("2006-01-02 15:04:05")
+	for _, c := range credits {
+		id := strings.ReplaceAll(c.MemoryID, "'", "''")
+		current, exists := priorities[c.MemoryID]
+		base := clampPriority(current - oldDelta[c.MemoryID])
+		applied := clampPriority(base+c.PriorityDelta) - base
+		delete(oldDelta, c.MemoryID)
+
+		fmt.Fprintf(&script, `INSERT INTO memory_credit (memory_id, decision_id, weight, outcome, successes, failures, priority_delta, recorded_at) VALUES ('%s', '%s', %f, %f, %f, %f, %f, '%s');`+"\n",
+			id, decisionID, c.Weight, c.Outcome, c.Successes, c.Failures, applied, now)
+		if !exists {
+			continue
+		}
+		fmt.Fprintf(&script, "UPDATE memories SET priority = %f WHERE id = '%s';\n", base+applied, id)
+		// Only the first outcome counts as a use of the memory
+		if !credited[c.MemoryID] {
+			fmt.Fprintf(&script, "UPDATE memories SET access_count = access_count + 1 WHERE id = '%s';\n", id)
+		}
+	}
+	for id, delta := range oldDelta {
+		current, exists := priorities[id]
+		if !exists {
+			continue
+		}
+		fmt.Fprintf(&script, "UPDATE memories SET priority = %f WHERE id = '%s';\n",
+			clampPriority(current-delta), strings.ReplaceAll(id, "'", "''"))
+	}
+
+	return script.String(), nil
+}
+
+// loadPriorities returns the current priority of each memory that exists
+func loadPriorities(ids []string) (map[string]float64, error) {
+	priorities := make(map[string]float64, len(ids))
+	ids = uniqueStrings(ids)
+	if len(ids) == 0 {
+		return priorities, nil
+	}
+
+	output, err := ExecDoltSQLJSON(fmt.Sprintf("SELECT id, priority FROM memories WHERE id IN (%s)", sqlList(ids)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load priorities: %w", err)
+	}
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, err
+	}
+	for _, row := range result.Rows {
+		priorities[models.AsString(row["id"])] = models.AsFloat64(row["priority"])
+	}
+	return priorities, nil
+}
+
+// clampPriority keeps a priority within 0-1
+func clampPriority(p float64) float64 {
+	return math.Min(1, math.Max(0, p))
+}
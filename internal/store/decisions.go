@@ -12,41 +12,142 @@ import (
 	"github.com/hargabyte/ami/internal/models"
 )
 
+// DecisionStatus is where a decision is in its lifecycle
+type DecisionStatus string
+
+const (
+	DecisionProposed   DecisionStatus = "proposed"   // Under consideration, not yet acted on
+	DecisionActive     DecisionStatus = "active"     // In effect
+	DecisionReverted   DecisionStatus = "reverted"   // Undone; see StatusReason
+	DecisionSuperseded DecisionStatus = "superseded" // Replaced by SupersededBy
+)
+
+// IsValid checks if the decision status is valid
+func (s DecisionStatus) IsValid() bool {
+	switch s {
+	case DecisionProposed, DecisionActive, DecisionReverted, DecisionSuperseded:
+		return true
+	default:
+		return false
+	}
+}
+
+// Alternative is an option that was considered and rejected
+type Alternative struct {
+	Option string `json:"option"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// ParseAlternative reads "option: reason for rejecting it"
+func ParseAlternative(s string) Alternative {
+	option, reason, _ := strings.Cut(s, ":")
+	return Alternative{Option: strings.TrimSpace(option), Reason: strings.TrimSpace(reason)}
+}
+
 // Decision represents a tracked decision and its outcome
 type Decision struct {
-	ID           string    `json:"id"`
-	TaskID       string    `json:"task_id"`
-	MemoryIDs    []string  `json:"memory_ids"`
-	DecisionText string    `json:"decision_text"`
-	Outcome      float64   `json:"outcome"`
-	Feedback     string    `json:"feedback"`
-	CommitHash   string    `json:"commit_hash"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           string         `json:"id"`
+	TaskID       string         `json:"task_id"`
+	OwnerID      string         `json:"owner_id"`
+	MemoryIDs    []string       `json:"memory_ids"`
+	DecisionText string         `json:"decision_text"`
+	Status       DecisionStatus `json:"status"`
+	Rationale    string         `json:"rationale,omitempty"`
+	Alternatives []Alternative  `json:"alternatives,omitempty"`
+	Confidence   *float64       `json:"confidence,omitempty"` // Predicted probability of success, if stated
+	Outcome      *float64       `json:"outcome"`              // nil until an outcome is recorded
+	Feedback     string         `json:"feedback"`
+	SupersededBy string         `json:"superseded_by,omitempty"`
+	StatusReason string         `json:"status_reason,omitempty"`
+	GitCommit    string         `json:"git_commit,omitempty"` // Code change the decision produced
+	Files        []string       `json:"files,omitempty"`      // Repo-relative paths the decision touched
+	CommitHash   string         `json:"commit_hash"`          // Dolt head of the memory store when tracked
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+// Pending reports whether the decision still awaits an outcome
+func (d Decision) Pending() bool {
+	return d.Outcome == nil
+}
+
+// decisionColumns is the column list shared by every decision query
+const decisionColumns = "id, task_id, owner_id, memory_ids, decision_text, status, rationale, alternatives, confidence, outcome, feedback, superseded_by, status_reason, git_commit, files, created_at, commit_hash"
+
+// TrackParams contains parameters for tracking a decision
+type TrackParams struct {
+	TaskID       string
+	OwnerID      string // Agent or person who made the decision; defaults to system
+	MemoryIDs    []string
+	DecisionText string
+	Status       DecisionStatus // Defaults to active
+	Rationale    string
+	Alternatives []Alternative
+	Confidence   *float64 // Predicted probability of success, 0-1
+	GitCommit    string   // Full hash of the project commit the decision produced
+	Files        []string // Repo-relative paths the decision touched
 }
 
 // TrackDecision tracks a new decision with the memories that informed it
 func TrackDecision(taskID string, memoryIDs []string, decisionText string, source string) (*Decision, error) {
+	return TrackDecisionWithParams(TrackParams{
+		TaskID:       taskID,
+		MemoryIDs:    memoryIDs,
+		DecisionText: decisionText,
+	})
+}
+
+// TrackDecisionWithParams tracks a new decision with its rationale and the
+// alternatives that were rejected
+func TrackDecisionWithParams(params TrackParams) (*Decision, error) {
+	if params.Status == "" {
+		params.Status = DecisionActive
+	}
+	if params.OwnerID == "" {
+		params.OwnerID = "system"
+	}
+	if params.Status != DecisionProposed && params.Status != DecisionActive {
+		return nil, fmt.Errorf("a new decision must be proposed or active, not %s", params.Status)
+	}
+	confidenceSQL := "NULL"
+	if params.Confidence != nil {
+		if *params.Confidence < 0 || *params.Confidence > 1 {
+			return nil, fmt.Errorf("confidence must be between 0.0 and 1.0")
+		}
+		confidenceSQL = fmt.Sprintf("%f", *params.Confidence)
+	}
+
 	// Generate UUID
 	id := uuid.New().String()
 	now := time.Now().Format("2006-01-02 15:04:05")
 
 	// Convert memory IDs to JSON
-	memoryIDsJSON, err := json.Marshal(memoryIDs)
+	memoryIDsJSON, err := json.Marshal(params.MemoryIDs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal memory IDs: %w", err)
 	}
+	alternativesJSON, err := json.Marshal(params.Alternatives)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal alternatives: %w", err)
+	}
+	filesJSON, err := json.Marshal(params.Files)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal files: %w", err)
+	}
 
 	// Escape single quotes
-	escapedDecisionText := strings.ReplaceAll(decisionText, "'", "''")
+	escapedDecisionText := strings.ReplaceAll(params.DecisionText, "'", "''")
+	escapedRationale := strings.ReplaceAll(params.Rationale, "'", "''")
+	escapedAlternatives := strings.ReplaceAll(string(alternativesJSON), "'", "''")
 
 	// Get current commit hash for temporal linking
 	commitHash, _ := db.GetHeadCommit()
 
-	// Insert decision
+	// Insert decision; outcome stays NULL until one is recorded
 	query := fmt.Sprintf(`
-		INSERT INTO decisions (id, task_id, memory_ids, decision_text, created_at, commit_hash)
-		VALUES ('%s', '%s', '%s', '%s', '%s', '%s')
-	`, id, taskID, string(memoryIDsJSON), escapedDecisionText, now, commitHash)
+		INSERT INTO decisions (id, task_id, owner_id, memory_ids, decision_text, status, rationale, alternatives, confidence, outcome, git_commit, files, created_at, commit_hash)
+		VALUES ('%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', %s, NULL, '%s', '%s', '%s', '%s')
+	`, id, strings.ReplaceAll(params.TaskID, "'", "''"), strings.ReplaceAll(params.OwnerID, "'", "''"), string(memoryIDsJSON), escapedDecisionText, params.Status, escapedRationale, escapedAlternatives, confidenceSQL,
+		strings.ReplaceAll(params.GitCommit, "'", "''"), strings.ReplaceAll(string(filesJSON), "'", "''"), now, commitHash)
 
 	_, err = db.ExecDoltSQL(query)
 	if err != nil {
@@ -54,11 +155,14 @@ func TrackDecision(taskID string, memoryIDs []string, decisionText string, sourc
 	}
 
 	// Create Dolt commit
-	excerpt := decisionText
+	excerpt := params.DecisionText
 	if len(excerpt) > 50 {
 		excerpt = excerpt[:50] + "..."
 	}
 	commitMsg := fmt.Sprintf("Track decision: %s", excerpt)
+	if params.Status == DecisionProposed {
+		commitMsg = fmt.Sprintf("Propose decision: %s", excerpt)
+	}
 
 	if err := DoltCommit(commitMsg); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
@@ -67,16 +171,112 @@ func TrackDecision(taskID string, memoryIDs []string, decisionText string, sourc
 	createdTime, _ := time.Parse("2006-01-02 15:04:05", now)
 	return &Decision{
 		ID:           id,
-		TaskID:       taskID,
-		MemoryIDs:    memoryIDs,
-		DecisionText: decisionText,
-		Outcome:      0.0,
+		TaskID:       params.TaskID,
+		OwnerID:      params.OwnerID,
+		MemoryIDs:    params.MemoryIDs,
+		DecisionText: params.DecisionText,
+		Status:       params.Status,
+		Rationale:    params.Rationale,
+		Alternatives: params.Alternatives,
+		Confidence:   params.Confidence,
+		GitCommit:    params.GitCommit,
+		Files:        params.Files,
 		CommitHash:   commitHash,
 		CreatedAt:    createdTime,
 	}, nil
 }
 
-// RecordOutcome records the outcome of a decision and reinforces linked memories if successful
+// ActivateDecision puts a proposed decision into effect
+func ActivateDecision(decisionID string) error {
+	decision, err := GetDecision(decisionID)
+	if err != nil {
+		return err
+	}
+	if decision.Status != DecisionProposed {
+		return fmt.Errorf("decision %s is %s, only proposed decisions can be activated", decisionID, decision.Status)
+	}
+
+	return setDecisionStatus(decisionID, DecisionActive, "", "",
+		fmt.Sprintf("Activate decision %s", decisionID))
+}
+
+// RevertDecision marks a decision as undone, with the reason why
+func RevertDecision(decisionID string, reason string) error {
+	decision, err := GetDecision(decisionID)
+	if err != nil {
+		return err
+	}
+	if decision.Status != DecisionActive && decision.Status != DecisionProposed {
+		return fmt.Errorf("decision %s is already %s", decisionID, decision.Status)
+	}
+
+	return setDecisionStatus(decisionID, DecisionReverted, reason, "",
+		fmt.Sprintf("Revert decision %s", decisionID))
+}
+
+// SupersedeDecision replaces oldID with newID. A proposed replacement
+// becomes active, since superseding only makes sense once it is in effect.
+func SupersedeDecision(oldID, newID string, reason string) error {
+	if oldID == newID {
+		return fmt.Errorf("a decision cannot supersede itself")
+	}
+	old, err := GetDecision(oldID)
+	if err != nil {
+		return fmt.Errorf("decision %s: %w", oldID, err)
+	}
+	replacement, err := GetDecision(newID)
+	if err != nil {
+		return fmt.Errorf("decision %s: %w", newID, err)
+	}
+	if old.Status != DecisionActive && old.Status != DecisionProposed {
+		return fmt.Errorf("decision %s is already %s", oldID, old.Status)
+	}
+	if replacement.Status != DecisionActive && replacement.Status != DecisionProposed {
+		return fmt.Errorf("replacement decision %s is %s", newID, replacement.Status)
+	}
+
+	if replacement.Status == DecisionProposed {
+		query := fmt.Sprintf("UPDATE decisions SET status = '%s' WHERE id = '%s'", DecisionActive, newID)
+		if _, err := db.ExecDoltSQL(query); err != nil {
+			return fmt.Errorf("failed to activate decision: %w", err)
+		}
+	}
+
+	return setDecisionStatus(oldID, DecisionSuperseded, reason, newID,
+		fmt.Sprintf("Supersede decision %s with %s", oldID, newID))
+}
+
+// setDecisionStatus writes a status change and commits it
+func setDecisionStatus(decisionID string, status DecisionStatus, reason string, supersededBy string, commitMsg string) error {
+	supersededSQL := "NULL"
+	if supersededBy != "" {
+		supersededSQL = fmt.Sprintf("'%s'", strings.ReplaceAll(supersededBy, "'", "''"))
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE decisions
+		SET status = '%s', status_reason = '%s', superseded_by = %s
+		WHERE id = '%s'
+	`, status, strings.ReplaceAll(reason, "'", "''"), supersededSQL, decisionID)
+
+	if _, err := db.ExecDoltSQL(query); err != nil {
+		return fmt.Errorf("failed to update decision: %w", err)
+	}
+
+	if reason != "" {
+		commitMsg += ": " + reason
+	}
+	if err := DoltCommit(commitMsg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+
+	return nil
+}
+
+// RecordOutcome records the outcome of a decision and credits the memories
+// that informed it: good outcomes raise their priority and confidence, bad
+// ones lower them. Re-recording replaces the earlier credit. Everything is
+// written in one Dolt commit.
 func RecordOutcome(decisionID string, outcome float64, feedback string) error {
 	// Get the decision first to retrieve linked memory IDs
 	decision, err := GetDecision(decisionID)
@@ -88,41 +288,30 @@ func RecordOutcome(decisionID string, outcome float64, feedback string) error {
 	escapedFeedback := strings.ReplaceAll(feedback, "'", "''")
 
 	// Update the decision
-	query := fmt.Sprintf(`
+	script := fmt.Sprintf(`
 		UPDATE decisions
 		SET outcome = %f, feedback = '%s'
-		WHERE id = '%s'
+		WHERE id = '%s';
 	`, outcome, escapedFeedback, decisionID)
 
-	_, err = db.ExecDoltSQL(query)
+	credits := assignCredit(decisionID, decision.MemoryIDs, outcome)
+	creditSQL, err := creditScript(decisionID, credits)
 	if err != nil {
-		return fmt.Errorf("failed to update decision: %w", err)
+		return err
 	}
+	script += creditSQL
 
-	// Reinforcement logic: if outcome > 0.8, boost priority of linked memories
-	if outcome > 0.8 && len(decision.MemoryIDs) > 0 {
-		for _, memID := range decision.MemoryIDs {
-			// Increase priority by 0.1
-			boostQuery := fmt.Sprintf(`
-				UPDATE memories
-				SET priority = priority + 0.1, access_count = access_count + 1
-				WHERE id = '%s'
-			`, memID)
-			_, err := db.ExecDoltSQL(boostQuery)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to boost memory %s: %v\n", memID, err)
-			}
-		}
-
-		// Commit the reinforcement
-		commitMsg := fmt.Sprintf("Reinforce memories for decision %s (outcome: %.2f)", decisionID, outcome)
-		if err := DoltCommit(commitMsg); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
-		}
+	if err := db.ExecDoltSQLScript(script); err != nil {
+		return fmt.Errorf("failed to record outcome: %w", err)
 	}
 
-	// Commit the outcome recording
 	commitMsg := fmt.Sprintf("Record outcome for decision %s: %.2f", decisionID, outcome)
+	if decision.Outcome != nil {
+		commitMsg = fmt.Sprintf("Re-record outcome for decision %s: %.2f (was %.2f)", decisionID, outcome, *decision.Outcome)
+	}
+	if len(credits) > 0 {
+		commitMsg += fmt.Sprintf(", credit %d memory(ies)", len(credits))
+	}
 	if err := DoltCommit(commitMsg); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
 	}
@@ -133,10 +322,10 @@ func RecordOutcome(decisionID string, outcome float64, feedback string) error {
 // GetDecision retrieves a decision by ID
 func GetDecision(decisionID string) (*Decision, error) {
 	query := fmt.Sprintf(`
-		SELECT id, task_id, memory_ids, decision_text, outcome, feedback, created_at, commit_hash
+		SELECT %s
 		FROM decisions
 		WHERE id = '%s'
-	`, decisionID)
+	`, decisionColumns, decisionID)
 
 	output, err := ExecDoltSQLJSON(query)
 	if err != nil {
@@ -151,17 +340,17 @@ func ListDecisions(taskID string) ([]Decision, error) {
 	var query string
 	if taskID != "" {
 		query = fmt.Sprintf(`
-			SELECT id, task_id, memory_ids, decision_text, outcome, feedback, created_at, commit_hash
+			SELECT %s
 			FROM decisions
 			WHERE task_id = '%s'
 			ORDER BY created_at DESC
-		`, taskID)
+		`, decisionColumns, taskID)
 	} else {
-		query = `
-			SELECT id, task_id, memory_ids, decision_text, outcome, feedback, created_at, commit_hash
+		query = fmt.Sprintf(`
+			SELECT %s
 			FROM decisions
 			ORDER BY created_at DESC
-		`
+		`, decisionColumns)
 	}
 
 	output, err := ExecDoltSQLJSON(query)
@@ -174,6 +363,20 @@ func ListDecisions(taskID string) ([]Decision, error) {
 
 // parseDecisionJSON parses a single decision from Dolt SQL JSON output
 func parseDecisionJSON(output string) (*Decision, error) {
+	decisions, err := parseDecisionsJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(decisions) == 0 {
+		return nil, fmt.Errorf("decision not found")
+	}
+
+	return &decisions[0], nil
+}
+
+// parseDecisionsJSON parses multiple decisions from Dolt SQL JSON output
+func parseDecisionsJSON(output string) ([]Decision, error) {
 	var result struct {
 		Rows []map[string]interface{} `json:"rows"`
 	}
@@ -181,35 +384,97 @@ func parseDecisionJSON(output string) (*Decision, error) {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
-	if len(result.Rows) == 0 {
-		return nil, fmt.Errorf("decision not found")
+	decisions := make([]Decision, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		decisions = append(decisions, parseDecisionRow(row))
 	}
 
-	row := result.Rows[0]
+	return decisions, nil
+}
 
+// parseDecisionRow converts one result row into a Decision
+func parseDecisionRow(row map[string]interface{}) Decision {
 	var memoryIDs []string
 	if memIDsStr := models.AsString(row["memory_ids"]); memIDsStr != "" {
 		json.Unmarshal([]byte(memIDsStr), &memoryIDs)
 	}
 
-	createdAt := models.AsTime(row["created_at"])
+	var alternatives []Alternative
+	if altStr := models.AsString(row["alternatives"]); altStr != "" {
+		json.Unmarshal([]byte(altStr), &alternatives)
+	}
+
+	var files []string
+	if filesStr := models.AsString(row["files"]); filesStr != "" {
+		json.Unmarshal([]byte(filesStr), &files)
+	}
+
+	status := DecisionStatus(models.AsString(row["status"]))
+	if status == "" {
+		status = DecisionActive
+	}
+
+	// NULL columns are left out of the row, so a missing outcome is pending
+	var outcome, confidence *float64
+	if v, ok := row["outcome"]; ok && v != nil {
+		o := models.AsFloat64(v)
+		outcome = &o
+	}
+	if v, ok := row["confidence"]; ok && v != nil {
+		c := models.AsFloat64(v)
+		confidence = &c
+	}
 
-	decision := &Decision{
+	return Decision{
 		ID:           models.AsString(row["id"]),
 		TaskID:       models.AsString(row["task_id"]),
+		OwnerID:      models.AsString(row["owner_id"]),
 		MemoryIDs:    memoryIDs,
 		DecisionText: models.AsString(row["decision_text"]),
+		Status:       status,
+		Rationale:    models.AsString(row["rationale"]),
+		Alternatives: alternatives,
+		Confidence:   confidence,
+		Outcome:      outcome,
 		Feedback:     models.AsString(row["feedback"]),
+		SupersededBy: models.AsString(row["superseded_by"]),
+		StatusReason: models.AsString(row["status_reason"]),
+		GitCommit:    models.AsString(row["git_commit"]),
+		Files:        files,
 		CommitHash:   models.AsString(row["commit_hash"]),
-		CreatedAt:    createdAt,
-		Outcome:      models.AsFloat64(row["outcome"]),
+		CreatedAt:    models.AsTime(row["created_at"]),
 	}
+}
 
-	return decision, nil
+// DecisionEvent is one change in a decision's history
+type DecisionEvent struct {
+	CommitHash string         `json:"commit_hash"`
+	Committer  string         `json:"committer"`
+	Date       time.Time      `json:"date"`
+	Message    string         `json:"message"`
+	Status     DecisionStatus `json:"status"`
+	Outcome    *float64       `json:"outcome"`
+	Feedback   string         `json:"feedback,omitempty"`
 }
 
-// parseDecisionsJSON parses multiple decisions from Dolt SQL JSON output
-func parseDecisionsJSON(output string) ([]Decision, error) {
+// GetDecisionTimeline returns every committed change to the decision, oldest
+// first. It reads dolt_diff_decisions rather than dolt_history_decisions
+// because the history table's commit_hash would clash with the decision's own.
+func GetDecisionTimeline(decisionID string) ([]DecisionEvent, error) {
+	query := fmt.Sprintf(`
+		SELECT d.to_status AS status, d.to_outcome AS outcome, d.to_feedback AS feedback,
+		       d.to_commit AS commit_hash, d.to_commit_date AS commit_date, l.committer, l.message
+		FROM dolt_diff_decisions d
+		LEFT JOIN dolt_log l ON l.commit_hash = d.to_commit
+		WHERE d.to_id = '%s'
+		ORDER BY d.to_commit_date ASC
+	`, decisionID)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load decision history: %w", err)
+	}
+
 	var result struct {
 		Rows []map[string]interface{} `json:"rows"`
 	}
@@ -217,26 +482,108 @@ func parseDecisionsJSON(output string) ([]Decision, error) {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
-	decisions := make([]Decision, 0, len(result.Rows))
+	events := make([]DecisionEvent, 0, len(result.Rows))
 	for _, row := range result.Rows {
-		var memoryIDs []string
-		if memIDsStr := models.AsString(row["memory_ids"]); memIDsStr != "" {
-			json.Unmarshal([]byte(memIDsStr), &memoryIDs)
-		}
+		d := parseDecisionRow(row)
+		events = append(events, DecisionEvent{
+			CommitHash: models.AsString(row["commit_hash"]),
+			Committer:  models.AsString(row["committer"]),
+			Date:       models.AsTime(row["commit_date"]),
+			Message:    models.AsString(row["message"]),
+			Status:     d.Status,
+			Outcome:    d.Outcome,
+			Feedback:   d.Feedback,
+		})
+	}
+
+	return events, nil
+}
 
-		decision := Decision{
-			ID:           models.AsString(row["id"]),
-			TaskID:       models.AsString(row["task_id"]),
-			MemoryIDs:    memoryIDs,
-			DecisionText: models.AsString(row["decision_text"]),
-			Feedback:     models.AsString(row["feedback"]),
-			CommitHash:   models.AsString(row["commit_hash"]),
-			CreatedAt:    models.AsTime(row["created_at"]),
-			Outcome:      models.AsFloat64(row["outcome"]),
+// GetSupersededDecisions returns the decisions that decisionID replaced
+func GetSupersededDecisions(decisionID string) ([]Decision, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM decisions
+		WHERE superseded_by = '%s'
+		ORDER BY created_at ASC
+	`, decisionColumns, decisionID)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve decisions: %w", err)
+	}
+
+	return parseDecisionsJSON(output)
+}
+
+// ListDecisionsForFile returns decisions that touched path: those recorded
+// with the file (or, for a directory, anything beneath it) and those whose
+// git commit changed it. path is resolved against the git repo containing dir.
+func ListDecisionsForFile(dir, path string) ([]Decision, error) {
+	rel, err := db.GitRelativePath(dir, path)
+	if err != nil {
+		return nil, err
+	}
+
+	escaped := strings.ReplaceAll(strings.ReplaceAll(rel, `\`, `\\`), "'", "''")
+	clauses := []string{
+		fmt.Sprintf(`JSON_CONTAINS(files, '"%s"')`, escaped),
+		fmt.Sprintf(`CAST(files AS CHAR) LIKE '%%"%s/%%'`, strings.NewReplacer("%", `\%`, "_", `\_`).Replace(escaped)),
+	}
+	if rel == "." {
+		clauses = []string{"JSON_LENGTH(files) > 0"}
+	}
+
+	// History covers decisions tracked with only a commit. Only commits some
+	// decision recorded are kept, so the IN list stays bounded by the
+	// decisions table however long the file's history is.
+	if commits, err := db.GitCommitsTouching(dir, path); err == nil && len(commits) > 0 {
+		tracked, err := trackedCommits()
+		if err != nil {
+			return nil, err
+		}
+		var linked []string
+		for _, c := range commits {
+			if tracked[c] {
+				linked = append(linked, c)
+			}
 		}
+		if len(linked) > 0 {
+			clauses = append(clauses, fmt.Sprintf("git_commit IN (%s)", sqlList(linked)))
+		}
+	}
 
-		decisions = append(decisions, decision)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM decisions
+		WHERE %s
+		ORDER BY created_at DESC
+	`, decisionColumns, strings.Join(clauses, " OR "))
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve decisions: %w", err)
 	}
 
-	return decisions, nil
+	return parseDecisionsJSON(output)
+}
+
+// trackedCommits returns the git commits recorded on any decision
+func trackedCommits() (map[string]bool, error) {
+	output, err := ExecDoltSQLJSON("SELECT DISTINCT git_commit FROM decisions WHERE git_commit IS NOT NULL AND git_commit != ''")
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve decision commits: %w", err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, err
+	}
+	commits := make(map[string]bool, len(result.Rows))
+	for _, row := range result.Rows {
+		commits[models.AsString(row["git_commit"])] = true
+	}
+	return commits, nil
 }
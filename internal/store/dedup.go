@@ -0,0 +1,208 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// DuplicatePolicy controls what AddMemoryWithParams does when new content
+// matches an existing memory
+type DuplicatePolicy string
+
+const (
+	DuplicateReject DuplicatePolicy = "reject" // Refuse the add
+	DuplicateMerge  DuplicatePolicy = "merge"  // Reinforce the existing memory instead
+	DuplicateLink   DuplicatePolicy = "link"   // Add anyway and link it as duplicate_of
+	DuplicateAllow  DuplicatePolicy = "allow"  // Skip duplicate detection entirely
+)
+
+// DuplicateSimilarityThreshold is the cosine similarity above which two
+// embeddings are treated as the same fact
+const DuplicateSimilarityThreshold = 0.95
+
+// IsValid checks if the duplicate policy is valid
+func (p DuplicatePolicy) IsValid() bool {
+	switch p {
+	case DuplicateReject, DuplicateMerge, DuplicateLink, DuplicateAllow:
+		return true
+	default:
+		return false
+	}
+}
+
+// DuplicateMatch describes an existing memory that new content duplicates
+type DuplicateMatch struct {
+	Memory     models.Memory `json:"memory"`
+	Method     string        `json:"method"` // "hash" or "embedding"
+	Similarity float32       `json:"similarity"`
+}
+
+// NormalizeContent lowercases text, strips punctuation and collapses whitespace
+// so trivially reworded facts hash identically
+func NormalizeContent(content string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(content) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// ContentHash returns the hex SHA-256 of the normalized content
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(NormalizeContent(content)))
+	return hex.EncodeToString(sum[:])
+}
+
+// duplicateScanLimit bounds how many of a team's memories one duplicate check
+// reads. Hash matches sort first, so only embedding matches among the
+// oldest memories of a large team can be missed.
+const duplicateScanLimit = 2000
+
+// DuplicateSearch narrows FindDuplicate
+type DuplicateSearch struct {
+	// MatchDeprecated also matches the exact content of deprecated memories,
+	// so facts a reviewer rejected aren't staged again by repeated imports
+	MatchDeprecated bool
+	// ExcludeIDs never match, e.g. the memory a new one supersedes or the
+	// sources a fact was derived from
+	ExcludeIDs []string
+}
+
+// FindDuplicate looks for an existing, non-deprecated memory in the same team
+// whose normalized hash matches or whose embedding is nearly identical.
+// Rows written before content_hash existed are hashed on the fly.
+func FindDuplicate(content string, teamID string, embedding []float32, search DuplicateSearch) (*DuplicateMatch, error) {
+	hash := ContentHash(content)
+
+	excluded := "'deprecated', 'archived'"
+	if search.MatchDeprecated {
+		excluded = "'archived'"
+	}
+
+	candidates := fmt.Sprintf("content_hash = '%s' OR content_hash IS NULL", hash)
+	columns := "id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, content_hash, expires_at"
+	if len(embedding) > 0 {
+		candidates += " OR embedding IS NOT NULL"
+		columns += ", embedding"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM memories
+		WHERE team_id = '%s'
+		  AND COALESCE(status, 'verified') NOT IN (%s)
+		  AND %s
+		  AND (%s)
+	`, columns, strings.ReplaceAll(teamID, "'", "''"), excluded, notExpiredClause, candidates)
+	if len(search.ExcludeIDs) > 0 {
+		query += fmt.Sprintf(" AND id NOT IN (%s)", sqlList(search.ExcludeIDs))
+	}
+	query += fmt.Sprintf(" ORDER BY COALESCE(content_hash = '%s', FALSE) DESC, created_at DESC LIMIT %d", hash, duplicateScanLimit)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query duplicate candidates: %w", err)
+	}
+
+	memories, err := parseMemoriesJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	// Exact (normalized) matches win over embedding matches
+	for _, m := range memories {
+		if ContentHash(m.Content) == hash {
+			return &DuplicateMatch{Memory: m, Method: "hash", Similarity: 1.0}, nil
+		}
+	}
+
+	if len(embedding) == 0 {
+		return nil, nil
+	}
+
+	var best *DuplicateMatch
+	for _, m := range memories {
+		// Deprecated rows only ever match exactly
+		if len(m.Embedding) != len(embedding) || m.Status == models.StatusDeprecated {
+			continue
+		}
+		score := CosineSimilarity(embedding, m.Embedding)
+		if score >= DuplicateSimilarityThreshold && (best == nil || score > best.Similarity) {
+			best = &DuplicateMatch{Memory: m, Method: "embedding", Similarity: score}
+		}
+	}
+
+	return best, nil
+}
+
+// mergeDuplicate reinforces an existing memory instead of adding a new copy:
+// priority and access count are bumped, any new tags are folded in, and an
+// expiring memory lives at least as long as the copy would have (expiresAt,
+// nil for never)
+func mergeDuplicate(existing models.Memory, priority float64, tags []string, expiresAt *time.Time) (*models.Memory, error) {
+	merged := existing
+	if merged.Tags == nil {
+		merged.Tags = models.Tags{}
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range existing.Tags {
+		seen[t] = true
+	}
+	for _, t := range tags {
+		if !seen[t] {
+			merged.Tags = append(merged.Tags, t)
+			seen[t] = true
+		}
+	}
+
+	// Take the higher of the two priorities, plus a small reinforcement, capped at 1.0
+	if priority > merged.Priority {
+		merged.Priority = priority
+	}
+	merged.Priority += 0.05
+	if merged.Priority > 1.0 {
+		merged.Priority = 1.0
+	}
+	merged.AccessCount++
+
+	expiresSQL := "expires_at"
+	if existing.ExpiresAt != nil {
+		if expiresAt == nil {
+			merged.ExpiresAt = nil
+			expiresSQL = "NULL"
+		} else if expiresAt.After(*existing.ExpiresAt) {
+			merged.ExpiresAt = expiresAt
+			expiresSQL = fmt.Sprintf("'%s'", expiresAt.Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	tagsJSON, err := json.Marshal(merged.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE memories
+		SET priority = %f, access_count = access_count + 1, accessed_at = NOW(), tags = '%s', content_hash = '%s', expires_at = %s
+		WHERE id = '%s'
+	`, merged.Priority, strings.ReplaceAll(string(tagsJSON), "'", "''"), ContentHash(existing.Content), expiresSQL, existing.ID)
+
+	if _, err := ExecDoltSQLJSON(query); err != nil {
+		return nil, fmt.Errorf("failed to merge duplicate: %w", err)
+	}
+
+	return &merged, nil
+}
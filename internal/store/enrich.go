@@ -0,0 +1,297 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// maxEnrichTags caps how many tags a single suggestion may add
+const maxEnrichTags = 5
+
+// Enrichment is the LLM's suggested metadata for one memory
+type Enrichment struct {
+	Tags     []string        `json:"tags"`
+	Category models.Category `json:"category,omitempty"`
+	Priority *float64        `json:"priority,omitempty"`
+}
+
+// TagVocabulary returns every canonical tag in use, most used first. Aliases
+// are left out since they resolve to a tag already in the list.
+func TagVocabulary() ([]string, error) {
+	counts, err := TagCounts()
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := LoadTagAliases()
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[string]int, len(counts))
+	for _, tc := range counts {
+		if t := resolveAlias(NormalizeTag(tc.Tag), aliases); t != "" {
+			merged[t] += tc.Count
+		}
+	}
+
+	vocab := make([]string, 0, len(merged))
+	for t := range merged {
+		vocab = append(vocab, t)
+	}
+	sort.Slice(vocab, func(i, j int) bool {
+		if merged[vocab[i]] != merged[vocab[j]] {
+			return merged[vocab[i]] > merged[vocab[j]]
+		}
+		return vocab[i] < vocab[j]
+	})
+	return vocab, nil
+}
+
+// SuggestEnrichment asks the local LLM for tags, a category and a priority
+// for content. Tags are restricted to vocabulary; only when the store has no
+// tags yet may the model coin its own.
+func SuggestEnrichment(ctx context.Context, ollama *db.OllamaClient, content string, vocabulary []string) (*Enrichment, error) {
+	tagRule := "Choose tags ONLY from this list: " + strings.Join(vocabulary, ", ")
+	if len(vocabulary) == 0 {
+		tagRule = "Use short lowercase tags; hierarchical tags use a slash, e.g. security/auth"
+	}
+
+	prompt := fmt.Sprintf(`
+Classify the following memory for an engineering team's knowledge base.
+%s. Pick at most %d tags, or none if nothing fits.
+Category is one of:
+  core     - fundamental, long-lived project facts and rules
+  semantic - general technical knowledge and conventions
+  episodic - a specific event, fix or observation
+  working  - short-lived task context
+Priority is 0.0-1.0: how important it is to remember this.
+Reply with exactly three lines:
+TAGS: <comma-separated tags>
+CATEGORY: <category>
+PRIORITY: <number>
+
+Memory:
+---
+%s
+---
+Answer:`, tagRule, maxEnrichTags, content)
+
+	response, err := ollama.Generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseEnrichment(response, vocabulary), nil
+}
+
+// parseEnrichment reads the TAGS/CATEGORY/PRIORITY lines, dropping anything
+// outside the vocabulary or out of range
+func parseEnrichment(response string, vocabulary []string) *Enrichment {
+	allowed := make(map[string]bool, len(vocabulary))
+	for _, t := range vocabulary {
+		allowed[t] = true
+	}
+
+	e := &Enrichment{}
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "- "))
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "TAGS":
+			for _, t := range strings.Split(value, ",") {
+				t = NormalizeTag(strings.Trim(strings.TrimSpace(t), `"'[]`))
+				if t == "" || t == "none" || seen[t] || len(e.Tags) >= maxEnrichTags {
+					continue
+				}
+				if len(vocabulary) > 0 && !allowed[t] {
+					continue
+				}
+				seen[t] = true
+				e.Tags = append(e.Tags, t)
+			}
+		case "CATEGORY":
+			cat := models.Category(strings.ToLower(strings.Trim(value, `"'.`)))
+			if cat.IsValid() {
+				e.Category = cat
+			}
+		case "PRIORITY":
+			if p, err := strconv.ParseFloat(strings.Trim(value, `"'`), 64); err == nil && p >= 0 && p <= 1 {
+				e.Priority = &p
+			}
+		}
+	}
+
+	return e
+}
+
+// EnrichOptions controls a backfill run
+type EnrichOptions struct {
+	TeamID    string
+	BatchSize int  // Memories per Dolt commit (default 20)
+	Limit     int  // Maximum memories to process, 0 for all
+	All       bool // Also enrich memories that already have tags
+	DryRun    bool
+	Ollama    *db.OllamaClient
+	Progress  func(done, total int)
+}
+
+// EnrichResult is the suggestion applied (or proposed) for one memory
+type EnrichResult struct {
+	ID         string          `json:"id"`
+	Content    string          `json:"content"`
+	Enrichment Enrichment      `json:"enrichment"`
+	Tags       []string        `json:"tags"`     // Tags after enrichment
+	Category   models.Category `json:"category"` // Category after enrichment
+	Priority   float64         `json:"priority"` // Priority after enrichment
+	Changed    bool            `json:"changed"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// BackfillEnrichment enriches existing memories in batches, one Dolt commit
+// per batch so an interrupted run keeps the work already done. By default only
+// untagged memories are processed. Suggested tags are added to existing ones;
+// the category only replaces the episodic default and priority only replaces
+// the 0.5 default, so nothing set deliberately is overwritten.
+func BackfillEnrichment(ctx context.Context, opts EnrichOptions) ([]EnrichResult, error) {
+	if opts.Ollama == nil {
+		return nil, fmt.Errorf("enrichment requires an Ollama client")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 20
+	}
+
+	whereClauses := []string{statusClause([]models.Status{models.StatusVerified, models.StatusUnderReview}), notExpiredClause}
+	if !opts.All {
+		whereClauses = append(whereClauses, "(tags IS NULL OR JSON_LENGTH(tags) = 0)")
+	}
+	if opts.TeamID != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("team_id = '%s'", strings.ReplaceAll(opts.TeamID, "'", "''")))
+	}
+	query := fmt.Sprintf(`
+		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, expires_at
+		FROM memories
+		WHERE %s
+		ORDER BY created_at DESC
+	`, strings.Join(whereClauses, " AND "))
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", opts.Limit)
+	}
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, err
+	}
+	memories, err := parseMemoriesJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	vocabulary, err := TagVocabulary()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]EnrichResult, 0, len(memories))
+	for start := 0; start < len(memories); start += opts.BatchSize {
+		batch := memories[start:min(start+opts.BatchSize, len(memories))]
+
+		var script strings.Builder
+		changed := 0
+		for _, m := range batch {
+			res := EnrichResult{ID: m.ID, Content: m.Content, Tags: m.Tags, Category: m.Category, Priority: m.Priority}
+
+			e, err := SuggestEnrichment(ctx, opts.Ollama, m.Content, vocabulary)
+			if err != nil {
+				res.Error = err.Error()
+				results = append(results, res)
+				continue
+			}
+			res.Enrichment = *e
+			res.Tags, res.Category, res.Priority = applyEnrichment(m.Tags, m.Category, m.Priority, e)
+			res.Changed = len(res.Tags) != len(m.Tags) || res.Category != m.Category || res.Priority != m.Priority
+			results = append(results, res)
+
+			if !res.Changed {
+				continue
+			}
+			tagsJSON, err := json.Marshal(res.Tags)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal tags: %w", err)
+			}
+			fmt.Fprintf(&script, "UPDATE memories SET tags = '%s', category = '%s', priority = %f WHERE id = '%s';\n",
+				strings.ReplaceAll(string(tagsJSON), "'", "''"), res.Category, res.Priority, m.ID)
+			changed++
+		}
+
+		if opts.Progress != nil {
+			opts.Progress(start+len(batch), len(memories))
+		}
+		if opts.DryRun || changed == 0 {
+			continue
+		}
+
+		if err := db.ExecDoltSQLScript(script.String()); err != nil {
+			return results, fmt.Errorf("failed to write enrichment batch: %w", err)
+		}
+		commitMsg := fmt.Sprintf("Enrich: tag and categorize %d memory(ies)", changed)
+		if err := DoltCommit(commitMsg); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+		}
+	}
+
+	return results, nil
+}
+
+// applyEnrichment merges a suggestion into a memory's metadata. Tags are
+// added; category and priority only replace the add defaults.
+func applyEnrichment(tags []string, category models.Category, priority float64, e *Enrichment) ([]string, models.Category, float64) {
+	merged := append([]string{}, tags...)
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		seen[NormalizeTag(t)] = true
+	}
+	for _, t := range e.Tags {
+		if !seen[t] {
+			seen[t] = true
+			merged = append(merged, t)
+		}
+	}
+
+	if category == models.CategoryEpisodic && e.Category != "" {
+		category = e.Category
+	}
+	if priority == 0.5 && e.Priority != nil {
+		priority = *e.Priority
+	}
+	return merged, category, priority
+}
+
+// EnrichAddParams fills in tags, category and priority for an add using the
+// LLM. Tags given by the caller are kept; category and priority are only
+// filled when the caller left them at their defaults.
+func EnrichAddParams(ctx context.Context, ollama *db.OllamaClient, params *AddParams) (*Enrichment, error) {
+	vocabulary, err := TagVocabulary()
+	if err != nil {
+		return nil, err
+	}
+	e, err := SuggestEnrichment(ctx, ollama, params.Content, vocabulary)
+	if err != nil {
+		return nil, err
+	}
+	params.Tags, params.Category, params.Priority = applyEnrichment(params.Tags, params.Category, params.Priority, e)
+	return e, nil
+}
@@ -0,0 +1,155 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// defaultWorkingTTL applies to working memories when AMI_WORKING_TTL is unset
+const defaultWorkingTTL = 72 * time.Hour
+
+// notExpiredClause excludes memories whose TTL has passed
+const notExpiredClause = "(expires_at IS NULL OR expires_at > NOW())"
+
+// visibleClause restricts a query to the given statuses (verified by
+// default) and hides expired memories. Asking for archived memories drops
+// the expiry check, since expired memories are what gets archived.
+func visibleClause(statuses []models.Status) string {
+	for _, st := range statuses {
+		if st == models.StatusArchived {
+			return statusClause(statuses)
+		}
+	}
+	return statusClause(statuses) + " AND " + notExpiredClause
+}
+
+// DefaultWorkingTTL returns the TTL given to working memories that don't set
+// one explicitly, read from AMI_WORKING_TTL (a Go duration, or "never")
+func DefaultWorkingTTL() time.Duration {
+	raw := os.Getenv("AMI_WORKING_TTL")
+	if raw == "" {
+		return defaultWorkingTTL
+	}
+	ttl, err := ParseTTL(raw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid AMI_WORKING_TTL %q, using %s\n", raw, defaultWorkingTTL)
+		return defaultWorkingTTL
+	}
+	return ttl
+}
+
+// ParseTTL parses a TTL such as "48h" or "30m". "never" and "0" mean no
+// expiry and return zero. A "d" suffix is accepted for days.
+func ParseTTL(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(strings.ToLower(raw))
+	if raw == "never" || raw == "0" {
+		return 0, nil
+	}
+	if strings.HasSuffix(raw, "d") {
+		var days float64
+		if _, err := fmt.Sscanf(strings.TrimSuffix(raw, "d"), "%g", &days); err != nil {
+			return 0, fmt.Errorf("invalid TTL %q", raw)
+		}
+		if days < 0 {
+			return 0, fmt.Errorf("TTL must be positive")
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+	ttl, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid TTL %q", raw)
+	}
+	if ttl < 0 {
+		return 0, fmt.Errorf("TTL must be positive")
+	}
+	return ttl, nil
+}
+
+// ExtendTTL pushes the expiry of the given memories to now + ttl. Only
+// memories that already expire are touched, so permanent memories stay permanent.
+func ExtendTTL(ids []string, ttl time.Duration) (int, error) {
+	if len(ids) == 0 || ttl <= 0 {
+		return 0, nil
+	}
+
+	expiresAt := time.Now().Add(ttl).Format("2006-01-02 15:04:05")
+	where := fmt.Sprintf("id IN (%s) AND expires_at IS NOT NULL AND expires_at < '%s'", sqlList(ids), expiresAt)
+
+	// Select first so the count is the rows actually extended
+	output, err := ExecDoltSQLJSON("SELECT id FROM memories WHERE " + where)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find memories to extend: %w", err)
+	}
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return 0, err
+	}
+	if len(result.Rows) == 0 {
+		return 0, nil
+	}
+	matched := make([]string, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		matched = append(matched, models.AsString(row["id"]))
+	}
+
+	query := fmt.Sprintf("UPDATE memories SET expires_at = '%s' WHERE id IN (%s)", expiresAt, sqlList(matched))
+	if _, err := db.ExecDoltSQL(query); err != nil {
+		return 0, fmt.Errorf("failed to extend TTL: %w", err)
+	}
+
+	commitMsg := fmt.Sprintf("Extend TTL of %d memory(ies) to %s", len(matched), expiresAt)
+	if err := DoltCommit(commitMsg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+
+	return len(matched), nil
+}
+
+// ExpireMemories archives every memory whose TTL has passed, in one Dolt
+// commit. With dryRun it only returns what would be archived.
+func ExpireMemories(dryRun bool) ([]models.Memory, error) {
+	query := fmt.Sprintf(`
+		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, expires_at
+		FROM memories
+		WHERE expires_at IS NOT NULL AND expires_at <= NOW() AND COALESCE(status, 'verified') != '%s'
+		ORDER BY expires_at ASC
+	`, models.StatusArchived)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expired memories: %w", err)
+	}
+
+	expired, err := parseMemoriesJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun || len(expired) == 0 {
+		return expired, nil
+	}
+
+	ids := make([]string, 0, len(expired))
+	for _, m := range expired {
+		ids = append(ids, m.ID)
+	}
+
+	update := fmt.Sprintf("UPDATE memories SET status = '%s' WHERE id IN (%s)", models.StatusArchived, sqlList(ids))
+	if _, err := db.ExecDoltSQL(update); err != nil {
+		return nil, fmt.Errorf("failed to archive expired memories: %w", err)
+	}
+
+	if err := DoltCommit(fmt.Sprintf("Expire %d memory(ies)", len(expired))); err != nil {
+		return nil, fmt.Errorf("failed to commit expiry: %w", err)
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// GraphEdge is a link as seen while walking from one memory to another.
+// Label is the relation name read in the walking direction.
+type GraphEdge struct {
+	MemoryLink
+	Label string `json:"label"`
+}
+
+// Neighbor is a memory reachable from the start of a neighbors query
+type Neighbor struct {
+	Memory models.Memory `json:"memory"`
+	Via    GraphEdge     `json:"via"`   // The edge that first reached this memory
+	Depth  int           `json:"depth"` // Hops from the starting memory
+}
+
+// Subgraph is a set of memories and the links between them
+type Subgraph struct {
+	Nodes []models.Memory `json:"nodes"`
+	Edges []MemoryLink    `json:"edges"`
+}
+
+// adjacency indexes links by both endpoints so walks can follow edges in
+// either direction
+func adjacency(links []MemoryLink) map[string][]GraphEdge {
+	adj := make(map[string][]GraphEdge)
+	for _, l := range links {
+		adj[l.FromID] = append(adj[l.FromID], GraphEdge{MemoryLink: l, Label: RelationLabel(l.Relation, true)})
+		adj[l.ToID] = append(adj[l.ToID], GraphEdge{MemoryLink: l, Label: RelationLabel(l.Relation, false)})
+	}
+	return adj
+}
+
+// other returns the endpoint of e that is not id
+func (e GraphEdge) other(id string) string {
+	if e.FromID == id {
+		return e.ToID
+	}
+	return e.FromID
+}
+
+// GetNeighbors returns memories within depth hops of id, following links in
+// both directions. relations restricts the walk to those canonical or
+// inverse relation names.
+func GetNeighbors(id string, depth int, relations []string) ([]Neighbor, error) {
+	if depth < 1 {
+		depth = 1
+	}
+
+	links, err := LoadLinks()
+	if err != nil {
+		return nil, err
+	}
+	adj := adjacency(links)
+
+	allowed := make(map[string]bool)
+	for _, r := range relations {
+		allowed[r] = true
+	}
+
+	type hop struct {
+		id    string
+		via   GraphEdge
+		depth int
+	}
+	seen := map[string]bool{id: true}
+	queue := []hop{{id: id}}
+	var hops []hop
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur.depth == depth {
+			continue
+		}
+		for _, e := range adj[cur.id] {
+			if len(allowed) > 0 && !allowed[e.Relation] && !allowed[e.Label] {
+				continue
+			}
+			next := e.other(cur.id)
+			if seen[next] {
+				continue
+			}
+			seen[next] = true
+			h := hop{id: next, via: e, depth: cur.depth + 1}
+			hops = append(hops, h)
+			queue = append(queue, h)
+		}
+	}
+
+	ids := make([]string, 0, len(hops))
+	for _, h := range hops {
+		ids = append(ids, h.id)
+	}
+	memories, err := GetMemoriesByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	neighbors := make([]Neighbor, 0, len(hops))
+	for _, h := range hops {
+		if m, ok := memories[h.id]; ok {
+			neighbors = append(neighbors, Neighbor{Memory: m, Via: h.via, Depth: h.depth})
+		}
+	}
+
+	return neighbors, nil
+}
+
+// FindPath returns the shortest chain of links between two memories,
+// ignoring link direction, or nil if they are not connected within maxDepth
+func FindPath(fromID, toID string, maxDepth int) ([]GraphEdge, error) {
+	if fromID == toID {
+		return []GraphEdge{}, nil
+	}
+
+	links, err := LoadLinks()
+	if err != nil {
+		return nil, err
+	}
+	adj := adjacency(links)
+
+	prev := map[string]GraphEdge{}
+	depth := map[string]int{fromID: 0}
+	queue := []string{fromID}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if maxDepth > 0 && depth[cur] >= maxDepth {
+			continue
+		}
+		for _, e := range adj[cur] {
+			next := e.other(cur)
+			if _, ok := depth[next]; ok {
+				continue
+			}
+			depth[next] = depth[cur] + 1
+			prev[next] = e
+			if next == toID {
+				// Walk back to the start
+				var path []GraphEdge
+				for at := toID; at != fromID; {
+					step := prev[at]
+					path = append([]GraphEdge{step}, path...)
+					at = step.other(at)
+				}
+				return path, nil
+			}
+			queue = append(queue, next)
+		}
+	}
+
+	return nil, nil
+}
+
+// GetSubgraph returns the memories matching the recall filters together
+// with the links among them. Unlike recall, supersession chains are kept.
+func GetSubgraph(opts RecallOptions) (*Subgraph, error) {
+	whereClause, err := recallWhereClause(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, expires_at
+		FROM memories
+		%s
+		ORDER BY priority DESC, accessed_at DESC
+	`, whereClause)
+	if opts.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", opts.Limit)
+	}
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load subgraph nodes: %w", err)
+	}
+
+	nodes, err := parseMemoriesJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	inGraph := make(map[string]bool, len(nodes))
+	for _, m := range nodes {
+		inGraph[m.ID] = true
+	}
+
+	links, err := LoadLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	edges := []MemoryLink{}
+	for _, l := range links {
+		if inGraph[l.FromID] && inGraph[l.ToID] {
+			edges = append(edges, l)
+		}
+	}
+
+	return &Subgraph{Nodes: nodes, Edges: edges}, nil
+}
@@ -0,0 +1,397 @@
+package store
+
+import (
+	_ "embed"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// ExportFormats lists the formats ExportGraph understands
+var ExportFormats = []string{"dot", "graphml", "mermaid", "cytoscape-json"}
+
+// ValidateExportFormat checks format before anything is written
+func ValidateExportFormat(format string) error {
+	for _, f := range ExportFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format '%s'. Must be one of: %s", format, strings.Join(ExportFormats, ", "))
+}
+
+// ExportNode is a memory flattened to the attributes graph tools display
+type ExportNode struct {
+	ID       string   `json:"id"`
+	Label    string   `json:"label"`
+	Content  string   `json:"content"`
+	Category string   `json:"category"`
+	Priority float64  `json:"priority"`
+	Decay    float64  `json:"decay"`
+	Team     string   `json:"team"`
+	Status   string   `json:"status"`
+	Tags     []string `json:"tags"`
+}
+
+// exportNodes converts subgraph memories to export nodes
+func exportNodes(g *Subgraph) []ExportNode {
+	now := time.Now()
+	nodes := make([]ExportNode, 0, len(g.Nodes))
+	for _, m := range g.Nodes {
+		label := m.Content
+		if runes := []rune(label); len(runes) > 60 {
+			label = string(runes[:57]) + "..."
+		}
+		status := string(m.Status)
+		if status == "" {
+			status = "verified"
+		}
+		nodes = append(nodes, ExportNode{
+			ID:       m.ID,
+			Label:    label,
+			Content:  m.Content,
+			Category: string(m.Category),
+			Priority: m.Priority,
+			Decay:    DecayScore(m, now),
+			Team:     m.TeamID,
+			Status:   status,
+			Tags:     append([]string{}, m.Tags...),
+		})
+	}
+	return nodes
+}
+
+// ExportGraph writes a subgraph in the given format
+func ExportGraph(g *Subgraph, format string, w io.Writer) error {
+	nodes := exportNodes(g)
+
+	switch format {
+	case "dot":
+		return exportDOT(nodes, g.Edges, w)
+	case "graphml":
+		return exportGraphML(nodes, g.Edges, w)
+	case "mermaid":
+		return exportMermaid(nodes, g.Edges, w)
+	case "cytoscape-json":
+		return exportCytoscape(nodes, g.Edges, w)
+	default:
+		return ValidateExportFormat(format)
+	}
+}
+
+func exportDOT(nodes []ExportNode, edges []MemoryLink, w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph ami {\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+	for _, n := range nodes {
+		label := fmt.Sprintf("%s\\n[%s] p=%.2f decay=%.2f\\nteam: %s", dotEscape(n.Label), n.Category, n.Priority, n.Decay, dotEscape(n.Team))
+		fmt.Fprintf(&b, "  %q [label=%s, category=%q, priority=%.2f, decay=%.4f, team=%q];\n",
+			n.ID, dotQuote(label), n.Category, n.Priority, n.Decay, n.Team)
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", e.FromID, e.ToID, e.Relation)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotEscape escapes backslashes and quotes in text placed inside a DOT label
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(s)
+}
+
+// dotQuote quotes an already-escaped DOT label, keeping \n as line breaks
+func dotQuote(s string) string {
+	return `"` + s + `"`
+}
+
+type graphMLDoc struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func exportGraphML(nodes []ExportNode, edges []MemoryLink, w io.Writer) error {
+	doc := graphMLDoc{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "content", For: "node", AttrName: "content", AttrType: "string"},
+			{ID: "category", For: "node", AttrName: "category", AttrType: "string"},
+			{ID: "priority", For: "node", AttrName: "priority", AttrType: "double"},
+			{ID: "decay", For: "node", AttrName: "decay", AttrType: "double"},
+			{ID: "team", For: "node", AttrName: "team", AttrType: "string"},
+			{ID: "status", For: "node", AttrName: "status", AttrType: "string"},
+			{ID: "relation", For: "edge", AttrName: "relation", AttrType: "string"},
+		},
+		Graph: graphMLGraph{EdgeDefault: "directed"},
+	}
+
+	for _, n := range nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: n.ID,
+			Data: []graphMLData{
+				{Key: "label", Value: n.Label},
+				{Key: "content", Value: n.Content},
+				{Key: "category", Value: n.Category},
+				{Key: "priority", Value: fmt.Sprintf("%.4f", n.Priority)},
+				{Key: "decay", Value: fmt.Sprintf("%.4f", n.Decay)},
+				{Key: "team", Value: n.Team},
+				{Key: "status", Value: n.Status},
+			},
+		})
+	}
+	for _, e := range edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: e.FromID,
+			Target: e.ToID,
+			Data:   []graphMLData{{Key: "relation", Value: e.Relation}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func exportMermaid(nodes []ExportNode, edges []MemoryLink, w io.Writer) error {
+	// Mermaid node IDs can't contain dashes reliably, so number them
+	alias := make(map[string]string, len(nodes))
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for i, n := range nodes {
+		alias[n.ID] = fmt.Sprintf("m%d", i)
+		label := fmt.Sprintf("%s<br/>[%s] p=%.2f decay=%.2f<br/>team: %s", n.Label, n.Category, n.Priority, n.Decay, n.Team)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", alias[n.ID], mermaidEscape(label))
+	}
+	for _, e := range edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", alias[e.FromID], e.Relation, alias[e.ToID])
+	}
+	b.WriteString("  classDef core fill:#fde68a\n")
+	b.WriteString("  classDef semantic fill:#bfdbfe\n")
+	b.WriteString("  classDef working fill:#e9d5ff\n")
+	b.WriteString("  classDef episodic fill:#bbf7d0\n")
+	for _, n := range nodes {
+		fmt.Fprintf(&b, "  class %s %s\n", alias[n.ID], n.Category)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidEscape replaces characters that break a quoted Mermaid label
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+}
+
+func exportCytoscape(nodes []ExportNode, edges []MemoryLink, w io.Writer) error {
+	type element struct {
+		Data interface{} `json:"data"`
+	}
+	type edgeData struct {
+		ID       string `json:"id"`
+		Source   string `json:"source"`
+		Target   string `json:"target"`
+		Relation string `json:"relation"`
+	}
+
+	doc := struct {
+		Elements struct {
+			Nodes []element `json:"nodes"`
+			Edges []element `json:"edges"`
+		} `json:"elements"`
+	}{}
+	doc.Elements.Nodes = []element{}
+	doc.Elements.Edges = []element{}
+
+	for _, n := range nodes {
+		doc.Elements.Nodes = append(doc.Elements.Nodes, element{Data: n})
+	}
+	for _, e := range edges {
+		doc.Elements.Edges = append(doc.Elements.Edges, element{Data: edgeData{
+			ID:       fmt.Sprintf("%s-%s-%s", e.FromID, e.Relation, e.ToID),
+			Source:   e.FromID,
+			Target:   e.ToID,
+			Relation: e.Relation,
+		}})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+//go:embed graph_explorer.html
+var graphExplorerHTML string
+
+// explorerHistory is one past version of a memory shown in the HTML explorer
+type explorerHistory struct {
+	Content    string `json:"content"`
+	CommitHash string `json:"commit_hash"`
+	Committer  string `json:"committer"`
+	CommitDate string `json:"commit_date"`
+}
+
+// explorerDecision is a decision shown in the HTML explorer
+type explorerDecision struct {
+	ID           string   `json:"id"`
+	TaskID       string   `json:"task_id"`
+	MemoryIDs    []string `json:"memory_ids"`
+	DecisionText string   `json:"decision_text"`
+	Status       string   `json:"status"`
+	Outcome      *float64 `json:"outcome"`
+	Feedback     string   `json:"feedback"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+// WriteGraphHTML writes a self-contained HTML explorer for the subgraph.
+// Node history and the decisions that used each node are embedded so the
+// file works offline.
+func WriteGraphHTML(g *Subgraph, w io.Writer) error {
+	history, err := loadExplorerHistory(g.Nodes)
+	if err != nil {
+		return err
+	}
+
+	all, err := ListDecisions("")
+	if err != nil {
+		return err
+	}
+	inGraph := make(map[string]bool, len(g.Nodes))
+	for _, m := range g.Nodes {
+		inGraph[m.ID] = true
+	}
+	decisions := []explorerDecision{}
+	for _, d := range all {
+		for _, id := range d.MemoryIDs {
+			if inGraph[id] {
+				decisions = append(decisions, explorerDecision{
+					ID:           d.ID,
+					TaskID:       d.TaskID,
+					MemoryIDs:    d.MemoryIDs,
+					DecisionText: d.DecisionText,
+					Status:       string(d.Status),
+					Outcome:      d.Outcome,
+					Feedback:     d.Feedback,
+					CreatedAt:    d.CreatedAt.Format("2006-01-02 15:04"),
+				})
+				break
+			}
+		}
+	}
+
+	edges := g.Edges
+	if edges == nil {
+		edges = []MemoryLink{}
+	}
+
+	// json.Marshal escapes <, > and &, so the payload can't close the script tag
+	payload, err := json.Marshal(map[string]interface{}{
+		"generated_at": time.Now().Format("2006-01-02 15:04"),
+		"nodes":        exportNodes(g),
+		"edges":        edges,
+		"history":      history,
+		"decisions":    decisions,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode graph: %w", err)
+	}
+
+	page := strings.Replace(graphExplorerHTML, "/*AMI_GRAPH_DATA*/", string(payload), 1)
+	_, err = io.WriteString(w, page)
+	return err
+}
+
+// loadExplorerHistory fetches the committed versions of every node in one query
+func loadExplorerHistory(nodes []models.Memory) (map[string][]explorerHistory, error) {
+	history := make(map[string][]explorerHistory)
+	if len(nodes) == 0 {
+		return history, nil
+	}
+
+	ids := make([]string, 0, len(nodes))
+	for _, m := range nodes {
+		ids = append(ids, m.ID)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, content, commit_hash, committer, commit_date
+		FROM dolt_history_memories
+		WHERE id IN (%s)
+		ORDER BY commit_date DESC
+	`, sqlList(ids))
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, err
+	}
+
+	for _, row := range result.Rows {
+		id := models.AsString(row["id"])
+		entry := explorerHistory{
+			Content:    models.AsString(row["content"]),
+			CommitHash: models.AsString(row["commit_hash"]),
+			Committer:  models.AsString(row["committer"]),
+			CommitDate: models.AsTime(row["commit_date"]).Format("2006-01-02 15:04"),
+		}
+		// Consecutive commits that didn't touch the content add nothing
+		if prev := history[id]; len(prev) > 0 && prev[len(prev)-1].Content == entry.Content {
+			history[id][len(prev)-1] = entry
+			continue
+		}
+		history[id] = append(history[id], entry)
+	}
+
+	return history, nil
+}
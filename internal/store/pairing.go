@@ -2,24 +2,67 @@ package store
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
+// PairingWireVersion is the current version of the PairingAction wire
+// format, one JSON object per line:
+//
+//	v1 (no "v" field): task_id, action, source
+//	v2: adds v, cwd, exit_code, duration_ms, git_branch and shell
+const PairingWireVersion = 2
+
+// pairingReportTimeout bounds how long a reporter waits on the daemon, so a
+// stuck daemon can never stall a shell prompt or a tool call
+const pairingReportTimeout = 250 * time.Millisecond
+
 type PairingAction struct {
-	TaskID string `json:"task_id"`
-	Action string `json:"action"`
-	Source string `json:"source"`
+	Version    int    `json:"v,omitempty"`
+	TaskID     string `json:"task_id"`
+	Action     string `json:"action"`
+	Source     string `json:"source"`
+	Cwd        string `json:"cwd,omitempty"`
+	ExitCode   *int   `json:"exit_code,omitempty"` // nil when not a command, or unknown
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	GitBranch  string `json:"git_branch,omitempty"`
+	Shell      string `json:"shell,omitempty"`
+}
+
+// Upgrade normalizes an action decoded from any supported wire version to
+// the current one, rejecting versions newer than this build understands and
+// actions with no source or content
+func (a *PairingAction) Upgrade() error {
+	if a.Source == "" || strings.TrimSpace(a.Action) == "" {
+		return fmt.Errorf("action needs a source and content")
+	}
+	if a.Version == 0 {
+		a.Version = 1
+	}
+	if a.Version > PairingWireVersion {
+		return fmt.Errorf("unsupported pairing wire version %d (this build speaks up to %d)", a.Version, PairingWireVersion)
+	}
+	a.Version = PairingWireVersion
+	return nil
 }
 
 func ReportToPairing(action PairingAction) error {
-	socketPath := "/tmp/ami-pairing.sock"
-	
-	conn, err := net.Dial("unix", socketPath)
+	socketPath := GetSocketPath()
+	if action.Version == 0 {
+		action.Version = PairingWireVersion
+	}
+
+	conn, err := net.DialTimeout("unix", socketPath, pairingReportTimeout)
 	if err != nil {
 		// Silent fail if daemon not running
 		return nil
 	}
 	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(pairingReportTimeout))
 
 	data, err := json.Marshal(action)
 	if err != nil {
@@ -30,6 +73,17 @@ func ReportToPairing(action PairingAction) error {
 	return err
 }
 
+// GetSocketPath returns the per-user socket the pairing daemon listens on
 func GetSocketPath() string {
-	return "/tmp/ami-pairing.sock"
+	return filepath.Join(PairingRuntimeDir(), "pairing.sock")
+}
+
+// PairingRuntimeDir returns the per-user directory holding the daemon's
+// socket, PID file and log: $XDG_RUNTIME_DIR/ami, or a private directory
+// under the system temp dir when that isn't set
+func PairingRuntimeDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "ami")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("ami-%d", os.Getuid()))
 }
@@ -0,0 +1,423 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// The daemon speaks line-delimited JSON on its socket. A line without an
+// "op" field is a PairingAction report and gets no reply, so fire-and-forget
+// reporters keep working. A line with an "op" is a PairingRequest and is
+// answered with exactly one PairingResponse line.
+
+// Pairing control operations
+const (
+	PairingOpStatus = "status" // Daemon and session stats
+	PairingOpRecall = "recall" // Recall memories through the daemon
+	PairingOpStop   = "stop"   // Shut the daemon down
+)
+
+// ErrPairingNotRunning is returned when no daemon answers on the socket
+var ErrPairingNotRunning = errors.New("pairing daemon is not running")
+
+// pairingRequestTimeout bounds control requests; recall can hit Ollama or
+// OpenAI, so it gets longer
+const (
+	pairingRequestTimeout = 2 * time.Second
+	pairingRecallTimeout  = 30 * time.Second
+)
+
+// PairingRequest asks the daemon to do something
+type PairingRequest struct {
+	Version int    `json:"v"`
+	Op      string `json:"op"`
+	Query   string `json:"query,omitempty"` // recall
+	Limit   int    `json:"limit,omitempty"` // recall
+}
+
+// PairingResponse answers one PairingRequest
+type PairingResponse struct {
+	Version  int             `json:"v"`
+	OK       bool            `json:"ok"`
+	Error    string          `json:"error,omitempty"`
+	Status   *PairingStatus  `json:"status,omitempty"`
+	Memories []models.Memory `json:"memories,omitempty"`
+}
+
+// PairingStats counts what a session has recorded
+type PairingStats struct {
+	Actions    int        `json:"actions"`
+	Commands   int        `json:"commands"` // Actions that reported an exit code
+	Failures   int        `json:"failures"` // Commands that exited non-zero
+	Dropped    int        `json:"dropped"`  // Reports in an unsupported wire version
+	LastAction *time.Time `json:"last_action,omitempty"`
+}
+
+// PairingStatus describes a running daemon
+type PairingStatus struct {
+	PID         int          `json:"pid"`
+	TaskID      string       `json:"task_id"`
+	Branch      string       `json:"branch,omitempty"`
+	Socket      string       `json:"socket"`
+	StartedAt   time.Time    `json:"started_at"`
+	WireVersion int          `json:"wire_version"`
+	Stats       PairingStats `json:"stats"`
+}
+
+// PairingDaemon serves one pairing session on the per-user socket
+type PairingDaemon struct {
+	Socket    string
+	StartedAt time.Time
+
+	// OnAction and OnError, when set, are told about each recorded action
+	// and each action that failed to record
+	OnAction func(SessionAction)
+	OnError  func(error)
+
+	listener  net.Listener
+	session   *PairingSession
+	mu        sync.Mutex
+	stats     PairingStats
+	closeOnce sync.Once
+}
+
+// PairingPIDPath returns the daemon's PID file
+func PairingPIDPath() string {
+	return filepath.Join(PairingRuntimeDir(), "pairing.pid")
+}
+
+// PairingLogPath returns where a detached daemon writes its output
+func PairingLogPath() string {
+	return filepath.Join(PairingRuntimeDir(), "pairing.log")
+}
+
+// EnsurePairingRuntimeDir creates the runtime directory private to the
+// user, refusing one that others can get into and we can't lock down
+func EnsurePairingRuntimeDir() error {
+	dir := PairingRuntimeDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		if err := os.Chmod(dir, 0o700); err != nil {
+			return fmt.Errorf("%s is accessible to other users: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// ListenPairing claims the pairing socket. A socket left behind by a daemon
+// that died is replaced; one that still answers is an error.
+func ListenPairing() (*PairingDaemon, error) {
+	if err := EnsurePairingRuntimeDir(); err != nil {
+		return nil, err
+	}
+
+	socketPath := GetSocketPath()
+	if _, err := os.Stat(socketPath); err == nil {
+		status, err := QueryPairing(PairingRequest{Op: PairingOpStatus})
+		if err == nil {
+			return nil, fmt.Errorf("pairing daemon already running (pid %d, task %s)", status.Status.PID, status.Status.TaskID)
+		}
+		// A daemon that accepts but answers slowly or oddly is still alive
+		if !errors.Is(err, ErrPairingNotRunning) {
+			return nil, fmt.Errorf("socket %s is in use: %w", socketPath, err)
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socketPath, 0o600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	if err := os.WriteFile(PairingPIDPath(), []byte(strconv.Itoa(os.Getpid())+"\n"), 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to write PID file: %w", err)
+	}
+
+	return &PairingDaemon{Socket: socketPath, StartedAt: time.Now(), listener: listener}, nil
+}
+
+// Serve records reports into session and answers requests until Close
+func (d *PairingDaemon) Serve(session *PairingSession) error {
+	// A resumed session carries its earlier actions into the stats
+	actions, err := session.Actions()
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.session = session
+	for _, a := range actions {
+		d.count(a)
+	}
+	d.mu.Unlock()
+
+	// Back off on accept errors such as EMFILE instead of spinning
+	var backoff time.Duration
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			if backoff == 0 {
+				backoff = 5 * time.Millisecond
+			} else if backoff *= 2; backoff > time.Second {
+				backoff = time.Second
+			}
+			d.reportError(fmt.Errorf("accept failed (retrying in %v): %w", backoff, err))
+			time.Sleep(backoff)
+			continue
+		}
+		backoff = 0
+		go d.handle(conn)
+	}
+}
+
+// Close stops the daemon and removes its socket and PID file
+func (d *PairingDaemon) Close() error {
+	var err error
+	d.closeOnce.Do(func() {
+		// The listener unlinks the socket itself
+		err = d.listener.Close()
+		if pid, _ := ReadPairingPID(); pid == os.Getpid() {
+			os.Remove(PairingPIDPath())
+		}
+	})
+	return err
+}
+
+// Status reports the daemon's session and stats
+func (d *PairingDaemon) Status() PairingStatus {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := PairingStatus{
+		PID:         os.Getpid(),
+		Socket:      d.Socket,
+		StartedAt:   d.StartedAt,
+		WireVersion: PairingWireVersion,
+		Stats:       d.stats,
+	}
+	if d.session != nil {
+		status.TaskID = d.session.TaskID
+		status.Branch = d.session.Branch
+	}
+	return status
+}
+
+// count adds an action to the stats; callers hold d.mu
+func (d *PairingDaemon) count(a SessionAction) {
+	d.stats.Actions++
+	if a.ExitCode != nil {
+		d.stats.Commands++
+		if *a.ExitCode != 0 {
+			d.stats.Failures++
+		}
+	}
+	at := a.RecordedAt
+	d.stats.LastAction = &at
+}
+
+func (d *PairingDaemon) handle(conn net.Conn) {
+	defer conn.Close()
+	decoder := json.NewDecoder(conn)
+	encoder := json.NewEncoder(conn)
+
+	for {
+		var line json.RawMessage
+		if err := decoder.Decode(&line); err != nil {
+			return
+		}
+
+		var probe struct {
+			Op string `json:"op"`
+		}
+		json.Unmarshal(line, &probe)
+		if probe.Op == "" {
+			d.record(line)
+			continue
+		}
+
+		var req PairingRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			return
+		}
+		resp := d.answer(req)
+		if err := encoder.Encode(resp); err != nil {
+			return
+		}
+		if req.Op == PairingOpStop && resp.OK {
+			d.Close()
+			return
+		}
+	}
+}
+
+// record stores one action report
+func (d *PairingDaemon) record(line json.RawMessage) {
+	var action PairingAction
+	err := json.Unmarshal(line, &action)
+	if err == nil {
+		err = action.Upgrade()
+	}
+	if err != nil {
+		d.mu.Lock()
+		d.stats.Dropped++
+		d.mu.Unlock()
+		d.reportError(fmt.Errorf("dropped action: %w", err))
+		return
+	}
+
+	entry, err := d.session.Record(action)
+	if entry != nil {
+		d.mu.Lock()
+		d.count(*entry)
+		d.mu.Unlock()
+		if d.OnAction != nil {
+			d.OnAction(*entry)
+		}
+	}
+	if err != nil {
+		d.reportError(err)
+	}
+}
+
+func (d *PairingDaemon) reportError(err error) {
+	if d.OnError != nil {
+		d.OnError(err)
+	}
+}
+
+// answer handles one control request
+func (d *PairingDaemon) answer(req PairingRequest) PairingResponse {
+	resp := PairingResponse{Version: PairingWireVersion, OK: true}
+	if req.Version > PairingWireVersion {
+		return PairingResponse{Version: PairingWireVersion, Error: fmt.Sprintf("unsupported pairing wire version %d", req.Version)}
+	}
+
+	switch req.Op {
+	case PairingOpStatus, PairingOpStop:
+		status := d.Status()
+		resp.Status = &status
+	case PairingOpRecall:
+		if strings.TrimSpace(req.Query) == "" {
+			return PairingResponse{Version: PairingWireVersion, Error: "recall needs a query"}
+		}
+		if req.Limit <= 0 {
+			req.Limit = 10
+		}
+		memories, err := RecallMemories(RecallOptions{Query: req.Query, Limit: req.Limit})
+		if err != nil {
+			return PairingResponse{Version: PairingWireVersion, Error: err.Error()}
+		}
+		// Embeddings are large and no use to a client
+		for i := range memories {
+			memories[i].Embedding = nil
+		}
+		resp.Memories = memories
+	default:
+		return PairingResponse{Version: PairingWireVersion, Error: fmt.Sprintf("unknown op %q", req.Op)}
+	}
+	return resp
+}
+
+// QueryPairing sends one control request to the running daemon. It returns
+// ErrPairingNotRunning when nothing answers on the socket.
+func QueryPairing(req PairingRequest) (*PairingResponse, error) {
+	if req.Version == 0 {
+		req.Version = PairingWireVersion
+	}
+	conn, err := net.DialTimeout("unix", GetSocketPath(), pairingReportTimeout)
+	if err != nil {
+		return nil, ErrPairingNotRunning
+	}
+	defer conn.Close()
+
+	timeout := pairingRequestTimeout
+	if req.Op == PairingOpRecall {
+		timeout = pairingRecallTimeout
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+	var resp PairingResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("no reply from pairing daemon: %w", err)
+	}
+	if !resp.OK {
+		return nil, fmt.Errorf("pairing daemon: %s", resp.Error)
+	}
+	return &resp, nil
+}
+
+// ReadPairingPID returns the PID recorded by the daemon, or 0 if there is
+// no PID file
+func ReadPairingPID() (int, error) {
+	data, err := os.ReadFile(PairingPIDPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return strconv.Atoi(string(bytes.TrimSpace(data)))
+}
+
+// StopPairingDaemon asks the daemon to shut down. A daemon that holds the
+// socket but doesn't answer is sent SIGTERM via its PID file. It returns the
+// PID that was stopped, and ErrPairingNotRunning (after clearing stale
+// files) when there was no daemon.
+func StopPairingDaemon() (int, error) {
+	resp, err := QueryPairing(PairingRequest{Op: PairingOpStop})
+	if err == nil {
+		return resp.Status.PID, nil
+	}
+
+	if errors.Is(err, ErrPairingNotRunning) {
+		// Don't signal the PID file's process: with no socket answering it
+		// may be an unrelated process that reused the PID
+		os.Remove(PairingPIDPath())
+		os.Remove(GetSocketPath())
+		return 0, ErrPairingNotRunning
+	}
+
+	pid, _ := ReadPairingPID()
+	if pid <= 0 {
+		return 0, err
+	}
+	process, findErr := os.FindProcess(pid)
+	if findErr == nil {
+		findErr = process.Signal(syscall.SIGTERM)
+	}
+	if findErr != nil {
+		return 0, fmt.Errorf("%v; failed to signal pid %d: %w", err, pid, findErr)
+	}
+	return pid, nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package store
+
+import "syscall"
+
+// DetachedSysProcAttr needs nothing extra where there are no controlling
+// terminals to detach from
+func DetachedSysProcAttr() *syscall.SysProcAttr {
+	return nil
+}
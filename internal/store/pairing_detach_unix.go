@@ -0,0 +1,11 @@
+//go:build unix
+
+package store
+
+import "syscall"
+
+// DetachedSysProcAttr starts a detached daemon in its own session, so it has
+// no controlling terminal and outlives the shell that started it
+func DetachedSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
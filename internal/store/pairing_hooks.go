@@ -0,0 +1,102 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PairingHookShells lists the shells PairingHook can integrate with
+var PairingHookShells = []string{"bash", "zsh", "fish"}
+
+// Each hook records the command line and a start time before it runs, and
+// after it finishes hands the exit code, duration and cwd to
+// "ami pairing report" in the background. Nothing is spawned unless the
+// daemon's socket exists, and the reporter itself gives up quickly, so a
+// stopped or wedged daemon never delays the prompt. The reporter resolves
+// the git branch from the cwd, keeping git out of the prompt path.
+
+const bashHook = `# ami pairing flight recorder (bash)
+__ami_pairing_preexec() {
+  [ -n "${COMP_LINE-}" ] && return
+  [ -n "${__ami_pairing_armed-}" ] || return
+  case "$BASH_COMMAND" in __ami_pairing_*) return ;; esac
+  __ami_pairing_armed=
+  __ami_pairing_cmd=$(HISTTIMEFORMAT= builtin history 1 | sed 's/^ *[0-9]*[* ] *//')
+  [ -n "$__ami_pairing_cmd" ] || __ami_pairing_cmd=$BASH_COMMAND
+  __ami_pairing_start=${EPOCHREALTIME/[.,]/}
+  : "${__ami_pairing_start:=$((SECONDS * 1000000))}"
+}
+__ami_pairing_precmd() {
+  local status=$?
+  if [ -n "${__ami_pairing_cmd-}" ] && [ -S %[2]s ]; then
+    local end=${EPOCHREALTIME/[.,]/}
+    : "${end:=$((SECONDS * 1000000))}"
+    ( %[1]s pairing report --shell bash --exit-code "$status" \
+        --duration-ms "$(( (end - __ami_pairing_start) / 1000 ))" --cwd "$PWD" \
+        -- "$__ami_pairing_cmd" >/dev/null 2>&1 & )
+  fi
+  __ami_pairing_cmd=
+}
+__ami_pairing_arm() { __ami_pairing_armed=1; }
+trap '__ami_pairing_preexec' DEBUG
+PROMPT_COMMAND="__ami_pairing_precmd${PROMPT_COMMAND:+; $PROMPT_COMMAND}; __ami_pairing_arm"
+`
+
+const zshHook = `# ami pairing flight recorder (zsh)
+zmodload zsh/datetime 2>/dev/null
+__ami_pairing_preexec() {
+  __ami_pairing_cmd=$1
+  __ami_pairing_start=$EPOCHREALTIME
+}
+__ami_pairing_precmd() {
+  local exit_code=$?
+  if [[ -n ${__ami_pairing_cmd-} && -S %[2]s ]]; then
+    local -i duration=$(( (EPOCHREALTIME - __ami_pairing_start) * 1000 ))
+    ( %[1]s pairing report --shell zsh --exit-code "$exit_code" \
+        --duration-ms "$duration" --cwd "$PWD" \
+        -- "$__ami_pairing_cmd" >/dev/null 2>&1 & )
+  fi
+  __ami_pairing_cmd=
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec __ami_pairing_preexec
+add-zsh-hook precmd __ami_pairing_precmd
+`
+
+// fish reports job completion for "&" inside functions, so the reporter is
+// backgrounded by sh instead
+const fishHook = `# ami pairing flight recorder (fish)
+function __ami_pairing_postexec --on-event fish_postexec
+    set -l exit_code $status
+    test -n "$argv[1]"; or return
+    test -S %[2]s; or return
+    command sh -c '"$0" "$@" >/dev/null 2>&1 &' %[1]s pairing report --shell fish \
+        --exit-code $exit_code --duration-ms $CMD_DURATION --cwd "$PWD" -- "$argv[1]"
+end
+`
+
+// PairingHook returns the shell snippet that reports every command line to
+// the pairing daemon through exe (the ami binary)
+func PairingHook(shell, exe string) (string, error) {
+	switch shell {
+	case "bash":
+		return fmt.Sprintf(bashHook, posixQuote(exe), posixQuote(GetSocketPath())), nil
+	case "zsh":
+		return fmt.Sprintf(zshHook, posixQuote(exe), posixQuote(GetSocketPath())), nil
+	case "fish":
+		return fmt.Sprintf(fishHook, fishQuote(exe), fishQuote(GetSocketPath())), nil
+	default:
+		return "", fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(PairingHookShells, ", "))
+	}
+}
+
+// posixQuote single-quotes s for bash and zsh
+func posixQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote single-quotes s for fish, where only \ and ' are special inside
+// single quotes
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,439 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// Pairing sessions: the daemon appends every action it receives to a JSONL
+// log per task. A session started with a branch also writes each action to
+// pairing_actions on pairing/<task>, one Dolt commit per action, leaving the
+// main branch untouched until the session is committed or discarded.
+
+// PairingBranchPrefix namespaces the draft branches of pairing sessions
+const PairingBranchPrefix = "pairing/"
+
+var unsafeTaskChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// PairingSession is the on-disk state of one task's pairing session
+type PairingSession struct {
+	TaskID    string    `json:"task_id"`
+	Branch    string    `json:"branch,omitempty"` // Draft Dolt branch; empty when only the log is kept
+	StartedAt time.Time `json:"started_at"`
+
+	mu sync.Mutex
+}
+
+// SessionAction is one line of a session log
+type SessionAction struct {
+	ID         string    `json:"id"`
+	RecordedAt time.Time `json:"recorded_at"`
+	PairingAction
+}
+
+// PairingCommitResult reports what CommitPairingSession wrote
+type PairingCommitResult struct {
+	TaskID  string   `json:"task_id"`
+	Branch  string   `json:"branch,omitempty"`
+	Actions int      `json:"actions"`
+	Facts   []string `json:"facts"`
+	Staged  []string `json:"staged"` // IDs of the under_review memories written
+}
+
+// PairingBranch returns the draft branch name for a task
+func PairingBranch(taskID string) string {
+	return PairingBranchPrefix + sessionName(taskID)
+}
+
+// sessionName makes a task ID safe for file and branch names
+func sessionName(taskID string) string {
+	name := strings.Trim(unsafeTaskChars.ReplaceAllString(taskID, "-"), "-.")
+	if name == "" {
+		name = "default"
+	}
+	return name
+}
+
+// pairingSessionDir returns the per-repository directory holding session
+// logs, under the user cache directory
+func pairingSessionDir() (string, error) {
+	repoPath, err := db.GetRepoPath()
+	if err != nil {
+		return "", err
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("no directory for pairing sessions: %w", err)
+	}
+	sum := sha256.Sum256([]byte(repoPath))
+	return filepath.Join(cacheDir, "ami", "pairing-"+hex.EncodeToString(sum[:6])), nil
+}
+
+// sessionPaths returns the metadata and log file paths for a task
+func sessionPaths(taskID string) (meta, log string, err error) {
+	dir, err := pairingSessionDir()
+	if err != nil {
+		return "", "", err
+	}
+	name := sessionName(taskID)
+	return filepath.Join(dir, name+".json"), filepath.Join(dir, name+".jsonl"), nil
+}
+
+// StartPairingSession opens the session for a task, resuming it if one is
+// already in progress. With useBranch the draft branch is created from the
+// current branch if it doesn't exist yet.
+func StartPairingSession(taskID string, useBranch bool) (*PairingSession, error) {
+	session, err := LoadPairingSession(taskID)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if session == nil {
+		session = &PairingSession{TaskID: taskID, StartedAt: time.Now()}
+	}
+
+	if useBranch && session.Branch == "" {
+		session.Branch = PairingBranch(taskID)
+		exists, err := doltBranchExists(session.Branch)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			if _, err := db.RunDolt("branch", session.Branch); err != nil {
+				return nil, fmt.Errorf("failed to create branch %s: %w", session.Branch, err)
+			}
+		}
+	}
+
+	metaPath, _, err := sessionPaths(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0o700); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(metaPath, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write session: %w", err)
+	}
+
+	return session, nil
+}
+
+// LoadPairingSession returns the session in progress for a task. The error
+// wraps os.ErrNotExist when there is none.
+func LoadPairingSession(taskID string) (*PairingSession, error) {
+	metaPath, _, err := sessionPaths(taskID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no pairing session for task %q: %w", taskID, err)
+		}
+		return nil, err
+	}
+	var session PairingSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("corrupt pairing session %s: %w", metaPath, err)
+	}
+	return &session, nil
+}
+
+// Record appends an action to the session log and, for branch sessions,
+// commits it to the draft branch. Safe for concurrent use.
+func (s *PairingSession) Record(action PairingAction) (*SessionAction, error) {
+	if action.TaskID == "" {
+		action.TaskID = s.TaskID
+	}
+	entry := SessionAction{ID: uuid.New().String(), RecordedAt: time.Now(), PairingAction: action}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, logPath, err := sessionPaths(s.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session log: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write session log: %w", err)
+	}
+
+	if s.Branch != "" {
+		// The checkout only lasts for this SQL session, so the working
+		// branch of the repository never changes
+		var script strings.Builder
+		fmt.Fprintf(&script, "CALL DOLT_CHECKOUT('%s');\n", strings.ReplaceAll(s.Branch, "'", "''"))
+		script.WriteString(pairingActionsScript([]SessionAction{entry}))
+		fmt.Fprintf(&script, "CALL DOLT_COMMIT('-Am', '%s');\n", escapeSQLText("Pairing: "+excerpt(entry.LogLine(), 60)))
+		if err := db.ExecDoltSQLScript(script.String()); err != nil {
+			return &entry, fmt.Errorf("logged, but failed to record on %s: %w", s.Branch, err)
+		}
+	}
+
+	return &entry, nil
+}
+
+// Actions returns every action recorded in the session, oldest first
+func (s *PairingSession) Actions() ([]SessionAction, error) {
+	_, logPath, err := sessionPaths(s.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(logPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var actions []SessionAction
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var a SessionAction
+		// A line cut short by a crash shouldn't lose the rest of the session
+		if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+			continue
+		}
+		actions = append(actions, a)
+	}
+	return actions, scanner.Err()
+}
+
+// LogLine renders an action as one line of a session transcript, with the
+// shell context of commands where it was reported
+func (a PairingAction) LogLine() string {
+	line := a.Action
+	if a.Source != "" {
+		line = fmt.Sprintf("[%s] %s", a.Source, a.Action)
+	}
+
+	var context []string
+	if a.ExitCode != nil {
+		context = append(context, fmt.Sprintf("exit %d", *a.ExitCode))
+	}
+	if a.DurationMs > 0 {
+		context = append(context, (time.Duration(a.DurationMs) * time.Millisecond).String())
+	}
+	if a.Cwd != "" {
+		where := a.Cwd
+		if a.GitBranch != "" {
+			where += "@" + a.GitBranch
+		}
+		context = append(context, "in "+where)
+	}
+	if len(context) > 0 {
+		line += " (" + strings.Join(context, ", ") + ")"
+	}
+	return line
+}
+
+// CommitPairingSession synthesizes the session's discoveries into
+// under_review semantic memories and lands them with the recorded actions on
+// the current branch in one Dolt commit. A draft branch is squash-merged and
+// deleted. If synthesis fails the session is left intact to retry.
+func CommitPairingSession(ctx context.Context, ollama *db.OllamaClient, taskID, teamID string) (*PairingCommitResult, error) {
+	if err := requirePairingIdle(taskID); err != nil {
+		return nil, err
+	}
+	session, err := LoadPairingSession(taskID)
+	if err != nil {
+		return nil, err
+	}
+	actions, err := session.Actions()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PairingCommitResult{TaskID: taskID, Branch: session.Branch, Actions: len(actions), Facts: []string{}, Staged: []string{}}
+	if len(actions) == 0 {
+		return result, DiscardPairingSession(taskID)
+	}
+
+	var transcript strings.Builder
+	for _, a := range actions {
+		transcript.WriteString(a.LogLine())
+		transcript.WriteString("\n")
+	}
+	facts, err := ExtractTechnicalFacts(ctx, ollama, transcript.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to synthesize session (kept for retry): %w", err)
+	}
+	result.Facts = append(result.Facts, facts...)
+
+	if session.Branch != "" {
+		// Merging needs a clean working set, and committing someone else's
+		// uncommitted changes here would sweep them into the session
+		dirty, err := doltWorkingSetDirty()
+		if err != nil {
+			return nil, err
+		}
+		if dirty {
+			return nil, fmt.Errorf("working set has uncommitted changes; commit or discard them before merging %s (session kept)", session.Branch)
+		}
+		if _, err := db.RunDolt("merge", "--squash", session.Branch); err != nil {
+			return nil, fmt.Errorf("failed to merge %s (session kept): %w", session.Branch, err)
+		}
+	} else if err := db.ExecDoltSQLScript(pairingActionsScript(actions)); err != nil {
+		return nil, fmt.Errorf("failed to record session actions: %w", err)
+	}
+
+	// Known facts, including ones a reviewer deprecated, are skipped
+	for _, fact := range facts {
+		added, err := insertMemory(AddParams{
+			Content:     fact,
+			OwnerID:     "system",
+			Category:    models.CategorySemantic,
+			Priority:    0.5,
+			Tags:        []string{"pairing"},
+			Source:      "pairing",
+			TeamID:      teamID,
+			Status:      models.StatusUnderReview,
+			OnDuplicate: DuplicateReject,
+			Search:      DuplicateSearch{MatchDeprecated: true},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to stage fact: %w", err)
+		}
+		if added.Action == "added" {
+			result.Staged = append(result.Staged, added.Memory.ID)
+		}
+	}
+
+	commitMsg := fmt.Sprintf("Pairing session %s: %d action(s), %d fact(s) staged for review", taskID, len(actions), len(result.Staged))
+	if err := DoltCommit(commitMsg); err != nil {
+		return nil, fmt.Errorf("failed to commit pairing session: %w", err)
+	}
+
+	return result, DiscardPairingSession(taskID)
+}
+
+// DiscardPairingSession deletes a session's log and draft branch without
+// keeping anything from it
+func DiscardPairingSession(taskID string) error {
+	if err := requirePairingIdle(taskID); err != nil {
+		return err
+	}
+	session, err := LoadPairingSession(taskID)
+	if err != nil {
+		return err
+	}
+
+	if session.Branch != "" {
+		exists, err := doltBranchExists(session.Branch)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if _, err := db.RunDolt("branch", "-D", session.Branch); err != nil {
+				return fmt.Errorf("failed to delete branch %s: %w", session.Branch, err)
+			}
+		}
+	}
+
+	metaPath, logPath, err := sessionPaths(taskID)
+	if err != nil {
+		return err
+	}
+	for _, path := range []string{logPath, metaPath} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// requirePairingIdle fails while a daemon may still be recording taskID, so
+// its branch and log aren't pulled out from under it
+func requirePairingIdle(taskID string) error {
+	resp, err := QueryPairing(PairingRequest{Op: PairingOpStatus})
+	if errors.Is(err, ErrPairingNotRunning) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot tell whether the pairing daemon is still recording: %w (stop it with: ami pairing stop)", err)
+	}
+	if resp.Status.TaskID == taskID {
+		return fmt.Errorf("pairing daemon (pid %d) is still recording task %s; stop it first with: ami pairing stop", resp.Status.PID, taskID)
+	}
+	return nil
+}
+
+// pairingActionsScript inserts session actions into pairing_actions
+func pairingActionsScript(actions []SessionAction) string {
+	var script strings.Builder
+	for _, a := range actions {
+		payload, _ := json.Marshal(a.PairingAction)
+		fmt.Fprintf(&script, "INSERT INTO pairing_actions (id, task_id, source, action, payload, recorded_at) VALUES ('%s', '%s', '%s', '%s', '%s', '%s');\n",
+			a.ID,
+			escapeSQLText(a.TaskID),
+			escapeSQLText(a.Source),
+			escapeSQLText(a.Action),
+			escapeSQLText(string(payload)),
+			a.RecordedAt.Format("2006-01-02 15:04:05"))
+	}
+	return script.String()
+}
+
+// escapeSQLText escapes free text for a single-quoted SQL literal. Shell
+// commands and JSON payloads carry backslashes, which Dolt would otherwise
+// read as escapes.
+func escapeSQLText(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), "'", "''")
+}
+
+// doltBranchExists reports whether a Dolt branch exists
+func doltBranchExists(name string) (bool, error) {
+	output, err := ExecDoltSQLJSON(fmt.Sprintf("SELECT name FROM dolt_branches WHERE name = '%s'", strings.ReplaceAll(name, "'", "''")))
+	if err != nil {
+		return false, err
+	}
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return false, err
+	}
+	return len(result.Rows) > 0, nil
+}
+
+// excerpt truncates s to n bytes with an ellipsis
+func excerpt(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -3,9 +3,11 @@ package store
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
 )
 
 // ExtractTechnicalFacts uses the local LLM to turn raw logs into structured facts
@@ -37,3 +39,177 @@ Facts:`, rawContent)
 
 	return facts, nil
 }
+
+// SynthesizeFact asks the local LLM to condense a cluster of related log
+// entries into a single durable fact
+func SynthesizeFact(ctx context.Context, ollama *db.OllamaClient, contents []string) (string, error) {
+	var entries strings.Builder
+	for _, c := range contents {
+		entries.WriteString("- ")
+		entries.WriteString(c)
+		entries.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`
+The following log entries describe related events.
+Condense them into ONE concise, definitive technical fact that will still be true and useful later.
+Drop timestamps, greetings and one-off details. Reply with the fact only, on one line.
+
+Entries:
+---
+%s---
+Fact:`, entries.String())
+
+	response, err := ollama.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	fact := strings.TrimSpace(response)
+	if line, _, found := strings.Cut(fact, "\n"); found {
+		fact = strings.TrimSpace(line)
+	}
+	fact = strings.TrimPrefix(fact, "- ")
+	fact = strings.Trim(fact, "\"")
+	if fact == "" {
+		return "", fmt.Errorf("model returned an empty fact")
+	}
+	return fact, nil
+}
+
+// SuggestedFact is a semantic fact proposed by reflection, with the memories
+// it was derived from
+type SuggestedFact struct {
+	Content    string   `json:"content"`
+	RelatedIDs []string `json:"related_ids"`
+	TeamID     string   `json:"team_id"`
+	FactID     string   `json:"fact_id,omitempty"`
+	Action     string   `json:"action,omitempty"`
+}
+
+// ReflectOnMemories asks the local LLM to synthesize semantic facts from a
+// window of memories. Related IDs in the response are matched back to the
+// window by prefix; facts citing no known memory are dropped.
+func ReflectOnMemories(ctx context.Context, ollama *db.OllamaClient, memories []models.Memory) ([]SuggestedFact, error) {
+	var entries strings.Builder
+	for _, m := range memories {
+		fmt.Fprintf(&entries, "[%s] %s\n", shortID(m.ID), m.Content)
+	}
+
+	prompt := fmt.Sprintf(`
+Review the following memories and suggest 1-3 semantic facts that capture the essential knowledge,
+eliminate redundant detail and keep high information density.
+For each fact, write exactly two lines:
+FACT: <concise, definitive statement>
+RELATED: <comma-separated IDs from the brackets below>
+
+Memories:
+---
+%s---
+Facts:`, entries.String())
+
+	response, err := ollama.Generate(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	byPrefix := make(map[string]models.Memory, len(memories))
+	for _, m := range memories {
+		byPrefix[shortID(m.ID)] = m
+	}
+
+	var facts []SuggestedFact
+	var current *SuggestedFact
+	for _, line := range strings.Split(response, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "- "))
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(upper, "FACT:"):
+			facts = append(facts, SuggestedFact{Content: strings.TrimSpace(line[len("FACT:"):])})
+			current = &facts[len(facts)-1]
+		case strings.HasPrefix(upper, "RELATED:") && current != nil:
+			for _, ref := range strings.Split(line[len("RELATED:"):], ",") {
+				ref = strings.Trim(strings.TrimSpace(ref), "[]")
+				if len(ref) > 8 {
+					ref = ref[:8]
+				}
+				if m, ok := byPrefix[ref]; ok {
+					current.RelatedIDs = append(current.RelatedIDs, m.ID)
+					if current.TeamID == "" {
+						current.TeamID = m.TeamID
+					}
+				}
+			}
+		}
+	}
+
+	valid := facts[:0]
+	for _, f := range facts {
+		if f.Content != "" && len(f.RelatedIDs) > 0 {
+			valid = append(valid, f)
+		}
+	}
+
+	return valid, nil
+}
+
+// StageSuggestedFacts writes each fact as an under_review semantic memory
+// linked derived_from to its sources, all in one Dolt commit
+func StageSuggestedFacts(facts []SuggestedFact, ownerID string) error {
+	if len(facts) == 0 {
+		return nil
+	}
+
+	err := writeOrRollBack(func() error {
+		for i := range facts {
+			f := &facts[i]
+			// The sources never count as duplicates, and other matches are
+			// linked so the fact still goes through review
+			added, err := insertMemory(AddParams{
+				Content:     f.Content,
+				OwnerID:     ownerID,
+				Category:    models.CategorySemantic,
+				Priority:    0.5,
+				Tags:        []string{"reflection"},
+				Source:      "reflect",
+				TeamID:      f.TeamID,
+				Status:      models.StatusUnderReview,
+				OnDuplicate: DuplicateLink,
+				Search:      DuplicateSearch{ExcludeIDs: f.RelatedIDs},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to stage fact: %w", err)
+			}
+			f.FactID = added.Memory.ID
+			f.Action = added.Action
+
+			for _, id := range f.RelatedIDs {
+				if id == f.FactID {
+					continue
+				}
+				if err := insertLink(f.FactID, id, "derived_from"); err != nil {
+					return fmt.Errorf("failed to link %s to %s: %w", f.FactID, id, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	commitMsg := fmt.Sprintf("Reflect: stage %d semantic fact(s) for review", len(facts))
+	if err := DoltCommit(commitMsg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+
+	return nil
+}
+
+// shortID returns the first 8 characters of an ID, as shown in listings
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
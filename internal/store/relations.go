@@ -0,0 +1,209 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// RelationType describes one kind of memory_links edge
+type RelationType struct {
+	Name        string `json:"name"`
+	Inverse     string `json:"inverse"`   // Name of the edge read from the other end
+	Symmetric   bool   `json:"symmetric"` // A->B implies B->A; stored once
+	Acyclic     bool   `json:"acyclic"`   // Chains of this relation may not loop
+	Description string `json:"description"`
+}
+
+// relationRegistry is the vocabulary LinkMemories accepts. Links are always
+// stored under the canonical name; inverse names are accepted and flipped.
+var relationRegistry = []RelationType{
+	{Name: "related", Inverse: "related", Symmetric: true, Description: "Loosely associated facts"},
+	{Name: "contradicts", Inverse: "contradicts", Symmetric: true, Description: "The two facts cannot both be true"},
+	{Name: "depends_on", Inverse: "required_by", Acyclic: true, Description: "The source only holds if the target holds"},
+	{Name: "derived_from", Inverse: "source_of", Acyclic: true, Description: "The source was synthesized from the target"},
+	{Name: "supersedes", Inverse: "superseded_by", Acyclic: true, Description: "The source is a newer version of the target"},
+	{Name: "duplicate_of", Inverse: "duplicated_by", Acyclic: true, Description: "The source restates the target"},
+	{Name: "part_of", Inverse: "has_part", Acyclic: true, Description: "The source is a component of the target"},
+	{Name: "example_of", Inverse: "has_example", Acyclic: true, Description: "The source illustrates the target"},
+}
+
+// Relations returns the registered relation types
+func Relations() []RelationType {
+	out := make([]RelationType, len(relationRegistry))
+	copy(out, relationRegistry)
+	return out
+}
+
+// LookupRelation resolves a relation or inverse name. inverted is true when
+// name is the inverse form, meaning the endpoints must be swapped to store it.
+func LookupRelation(name string) (rel RelationType, inverted bool, ok bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, r := range relationRegistry {
+		if r.Name == name {
+			return r, false, true
+		}
+		if r.Inverse == name {
+			return r, !r.Symmetric, true
+		}
+	}
+	return RelationType{}, false, false
+}
+
+// RelationLabel names an edge as seen from one of its endpoints. Links
+// written before the registry existed fall back to "<relation> (inverse)".
+func RelationLabel(relation string, outgoing bool) string {
+	if outgoing {
+		return relation
+	}
+	if r, _, ok := LookupRelation(relation); ok && r.Name == relation {
+		return r.Inverse
+	}
+	return relation + " (inverse)"
+}
+
+// normalizeLink validates a proposed link against the registry and returns
+// the canonical form it is stored in
+func normalizeLink(fromID, toID, relation string) (string, string, string, error) {
+	if fromID == toID {
+		return "", "", "", fmt.Errorf("a memory cannot be linked to itself")
+	}
+
+	rel, inverted, ok := LookupRelation(relation)
+	if !ok {
+		names := make([]string, 0, len(relationRegistry)*2)
+		for _, r := range relationRegistry {
+			names = append(names, r.Name)
+			if !r.Symmetric {
+				names = append(names, r.Inverse)
+			}
+		}
+		sort.Strings(names)
+		return "", "", "", fmt.Errorf("unknown relation '%s'. Must be one of: %s", relation, strings.Join(names, ", "))
+	}
+	if inverted {
+		fromID, toID = toID, fromID
+	}
+	// Symmetric links are stored once, with the lower ID first
+	if rel.Symmetric && toID < fromID {
+		fromID, toID = toID, fromID
+	}
+
+	found, err := GetMemoriesByIDs([]string{fromID, toID})
+	if err != nil {
+		return "", "", "", err
+	}
+	for _, id := range []string{fromID, toID} {
+		if _, ok := found[id]; !ok {
+			return "", "", "", fmt.Errorf("memory not found: %s", id)
+		}
+	}
+
+	if rel.Acyclic {
+		edges, err := LoadLinks(rel.Name)
+		if err != nil {
+			return "", "", "", err
+		}
+		if reaches(edges, toID, fromID) {
+			return "", "", "", fmt.Errorf("linking %s %s %s would create a %s cycle", fromID, rel.Name, toID, rel.Name)
+		}
+	}
+
+	return fromID, toID, rel.Name, nil
+}
+
+// reaches reports whether target can be reached from start along edges
+func reaches(edges []MemoryLink, start, target string) bool {
+	next := make(map[string][]string)
+	for _, e := range edges {
+		next[e.FromID] = append(next[e.FromID], e.ToID)
+	}
+
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == target {
+			return true
+		}
+		for _, n := range next[cur] {
+			if !seen[n] {
+				seen[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return false
+}
+
+// MemoryLink is one row of memory_links
+type MemoryLink struct {
+	FromID   string `json:"from_id"`
+	ToID     string `json:"to_id"`
+	Relation string `json:"relation"`
+}
+
+// LoadLinks returns every link, or only those of the given relations
+func LoadLinks(relations ...string) ([]MemoryLink, error) {
+	query := "SELECT from_id, to_id, relation FROM memory_links"
+	if len(relations) > 0 {
+		query += fmt.Sprintf(" WHERE relation IN (%s)", sqlList(relations))
+	}
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load links: %w", err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, err
+	}
+
+	links := make([]MemoryLink, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		links = append(links, MemoryLink{
+			FromID:   models.AsString(row["from_id"]),
+			ToID:     models.AsString(row["to_id"]),
+			Relation: models.AsString(row["relation"]),
+		})
+	}
+
+	return links, nil
+}
+
+// GetMemoriesByIDs fetches several memories at once, keyed by ID. Missing
+// IDs are simply absent from the map.
+func GetMemoriesByIDs(ids []string) (map[string]models.Memory, error) {
+	found := make(map[string]models.Memory)
+	if len(ids) == 0 {
+		return found, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, expires_at
+		FROM memories
+		WHERE id IN (%s)
+	`, sqlList(ids))
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve memories: %w", err)
+	}
+
+	memories, err := parseMemoriesJSON(output)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range memories {
+		found[m.ID] = m
+	}
+
+	return found, nil
+}
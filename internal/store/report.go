@@ -0,0 +1,416 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ReportFormats are the formats WriteDecisionReport understands
+var ReportFormats = []string{"table", "markdown"}
+
+// outcomeBuckets is the number of histogram bins across 0.0-1.0
+const outcomeBuckets = 5
+
+// ReportOptions controls what BuildDecisionReport covers
+type ReportOptions struct {
+	Since        time.Duration // Only decisions tracked within this window; 0 for all
+	PendingDays  int           // Flag decisions pending longer than this (default 14)
+	TopMemories  int           // Memories listed at each end of the outcome range (default 5)
+	MinDecisions int           // Recorded decisions a memory needs to be ranked (default 2)
+}
+
+// OutcomeStats is the outcome distribution of one group of decisions
+type OutcomeStats struct {
+	Key       string              `json:"key"`
+	Decisions int                 `json:"decisions"`
+	Recorded  int                 `json:"recorded"`
+	Pending   int                 `json:"pending"`
+	Mean      float64             `json:"mean"`
+	Min       float64             `json:"min"`
+	Max       float64             `json:"max"`
+	Histogram [outcomeBuckets]int `json:"histogram"` // Counts for 0-0.2, 0.2-0.4, ... 0.8-1.0
+}
+
+// add folds one decision into the stats
+func (s *OutcomeStats) add(d Decision) {
+	s.Decisions++
+	if d.Outcome == nil {
+		s.Pending++
+		return
+	}
+	o := *d.Outcome
+	if s.Recorded == 0 || o < s.Min {
+		s.Min = o
+	}
+	if s.Recorded == 0 || o > s.Max {
+		s.Max = o
+	}
+	// Running mean keeps add independent of the final count
+	s.Recorded++
+	s.Mean += (o - s.Mean) / float64(s.Recorded)
+	s.Histogram[min(int(o*outcomeBuckets), outcomeBuckets-1)]++
+}
+
+// MemoryOutcome is a memory ranked by the outcomes of decisions it informed
+type MemoryOutcome struct {
+	ID          string  `json:"id"`
+	Content     string  `json:"content"`
+	Decisions   int     `json:"decisions"`
+	MeanOutcome float64 `json:"mean_outcome"`
+	Confidence  float64 `json:"confidence"` // From the credit track record
+}
+
+// StaleDecision is a decision still waiting for an outcome
+type StaleDecision struct {
+	Decision
+	AgeDays int `json:"age_days"`
+}
+
+// DecisionReport summarizes tracked decisions for a retrospective
+type DecisionReport struct {
+	GeneratedAt  time.Time       `json:"generated_at"`
+	Since        *time.Time      `json:"since,omitempty"`
+	PendingDays  int             `json:"pending_days"`
+	Overall      OutcomeStats    `json:"overall"`
+	ByTask       []OutcomeStats  `json:"by_task"`
+	ByOwner      []OutcomeStats  `json:"by_owner"`
+	ByTag        []OutcomeStats  `json:"by_tag"`
+	HighMemories []MemoryOutcome `json:"high_memories"`
+	LowMemories  []MemoryOutcome `json:"low_memories"`
+	Stale        []StaleDecision `json:"stale"`
+}
+
+// BuildDecisionReport groups decisions by task, owner and the tags of the
+// memories behind them, ranks memories by the outcomes they led to and lists
+// decisions that have waited too long for an outcome
+func BuildDecisionReport(opts ReportOptions) (*DecisionReport, error) {
+	if opts.PendingDays <= 0 {
+		opts.PendingDays = 14
+	}
+	if opts.TopMemories <= 0 {
+		opts.TopMemories = 5
+	}
+	if opts.MinDecisions <= 0 {
+		opts.MinDecisions = 2
+	}
+
+	now := time.Now()
+	report := &DecisionReport{
+		GeneratedAt: now,
+		PendingDays: opts.PendingDays,
+		Overall:     OutcomeStats{Key: "all"},
+		LowMemories: []MemoryOutcome{},
+		Stale:       []StaleDecision{},
+	}
+	if opts.Since > 0 {
+		since := now.Add(-opts.Since)
+		report.Since = &since
+	}
+
+	all, err := ListDecisions("")
+	if err != nil {
+		return nil, err
+	}
+	decisions := all[:0]
+	for _, d := range all {
+		if report.Since == nil || !d.CreatedAt.Before(*report.Since) {
+			decisions = append(decisions, d)
+		}
+	}
+
+	var memoryIDs []string
+	for _, d := range decisions {
+		memoryIDs = append(memoryIDs, d.MemoryIDs...)
+	}
+	memories, err := GetMemoriesByIDs(uniqueStrings(memoryIDs))
+	if err != nil {
+		return nil, err
+	}
+	records, err := LoadTrackRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	byTask := make(map[string]*OutcomeStats)
+	byOwner := make(map[string]*OutcomeStats)
+	byTag := make(map[string]*OutcomeStats)
+	group := func(groups map[string]*OutcomeStats, key string, d Decision) {
+		if key == "" {
+			key = "(none)"
+		}
+		if groups[key] == nil {
+			groups[key] = &OutcomeStats{Key: key}
+		}
+		groups[key].add(d)
+	}
+
+	type memoryTotal struct {
+		sum   float64
+		count int
+	}
+	perMemory := make(map[string]*memoryTotal)
+
+	for _, d := range decisions {
+		report.Overall.add(d)
+		group(byTask, d.TaskID, d)
+		group(byOwner, d.OwnerID, d)
+
+		tags := make(map[string]bool)
+		for _, id := range uniqueStrings(d.MemoryIDs) {
+			if m, ok := memories[id]; ok {
+				for _, t := range m.Tags {
+					tags[NormalizeTag(t)] = true
+				}
+			}
+			if d.Outcome != nil {
+				if perMemory[id] == nil {
+					perMemory[id] = &memoryTotal{}
+				}
+				perMemory[id].sum += *d.Outcome
+				perMemory[id].count++
+			}
+		}
+		for t := range tags {
+			group(byTag, t, d)
+		}
+
+		// Reverted and superseded decisions will never get an outcome
+		if d.Pending() && (d.Status == DecisionActive || d.Status == DecisionProposed) {
+			age := int(now.Sub(d.CreatedAt).Hours() / 24)
+			if age >= opts.PendingDays {
+				report.Stale = append(report.Stale, StaleDecision{Decision: d, AgeDays: age})
+			}
+		}
+	}
+
+	report.ByTask = sortedStats(byTask)
+	report.ByOwner = sortedStats(byOwner)
+	report.ByTag = sortedStats(byTag)
+	sort.Slice(report.Stale, func(i, j int) bool { return report.Stale[i].AgeDays > report.Stale[j].AgeDays })
+
+	var ranked []MemoryOutcome
+	for id, t := range perMemory {
+		if t.count < opts.MinDecisions {
+			continue
+		}
+		mo := MemoryOutcome{ID: id, Decisions: t.count, MeanOutcome: t.sum / float64(t.count), Confidence: 0.5}
+		if m, ok := memories[id]; ok {
+			mo.Content = m.Content
+		}
+		if r, ok := records[id]; ok {
+			mo.Confidence = r.Confidence
+		}
+		ranked = append(ranked, mo)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].MeanOutcome != ranked[j].MeanOutcome {
+			return ranked[i].MeanOutcome > ranked[j].MeanOutcome
+		}
+		return ranked[i].Decisions > ranked[j].Decisions
+	})
+	// The two lists never share a memory, even when few are ranked
+	n := min(opts.TopMemories, (len(ranked)+1)/2)
+	report.HighMemories = append([]MemoryOutcome{}, ranked[:n]...)
+	for i := len(ranked) - 1; i >= n && len(report.LowMemories) < opts.TopMemories; i-- {
+		report.LowMemories = append(report.LowMemories, ranked[i])
+	}
+
+	return report, nil
+}
+
+// sortedStats orders groups by decision count, then key
+func sortedStats(groups map[string]*OutcomeStats) []OutcomeStats {
+	out := make([]OutcomeStats, 0, len(groups))
+	for _, s := range groups {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Decisions != out[j].Decisions {
+			return out[i].Decisions > out[j].Decisions
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// WriteDecisionReport renders the report as a plain table or markdown
+func WriteDecisionReport(r *DecisionReport, format string, w io.Writer) error {
+	switch format {
+	case "table":
+		return writeReportTable(r, w)
+	case "markdown":
+		return writeReportMarkdown(r, w)
+	default:
+		return fmt.Errorf("unknown report format '%s'. Must be one of: %s", format, strings.Join(ReportFormats, ", "))
+	}
+}
+
+// histogramBar draws the outcome histogram with block characters
+func histogramBar(h [outcomeBuckets]int) string {
+	levels := []rune("·▁▂▃▄▅▆▇█")
+	peak := 0
+	for _, c := range h {
+		peak = max(peak, c)
+	}
+	var b strings.Builder
+	for _, c := range h {
+		if peak == 0 {
+			b.WriteRune(levels[0])
+			continue
+		}
+		b.WriteRune(levels[int(math.Ceil(float64(c)/float64(peak)*float64(len(levels)-1)))])
+	}
+	return b.String()
+}
+
+// statsCells formats the numeric columns shared by every group table
+func statsCells(s OutcomeStats) []string {
+	mean, spread := "-", "-"
+	if s.Recorded > 0 {
+		mean = fmt.Sprintf("%.2f", s.Mean)
+		spread = fmt.Sprintf("%.2f-%.2f", s.Min, s.Max)
+	}
+	return []string{
+		fmt.Sprintf("%d", s.Decisions),
+		fmt.Sprintf("%d", s.Recorded),
+		fmt.Sprintf("%d", s.Pending),
+		mean,
+		spread,
+		histogramBar(s.Histogram),
+	}
+}
+
+// reportSections pairs each grouping with its title
+func reportSections(r *DecisionReport) []struct {
+	title string
+	stats []OutcomeStats
+} {
+	return []struct {
+		title string
+		stats []OutcomeStats
+	}{
+		{"By task", r.ByTask},
+		{"By owner", r.ByOwner},
+		{"By tag", r.ByTag},
+	}
+}
+
+// memorySections pairs each memory ranking with its title
+func memorySections(r *DecisionReport) []struct {
+	title    string
+	memories []MemoryOutcome
+} {
+	return []struct {
+		title    string
+		memories []MemoryOutcome
+	}{
+		{"Memories behind the best outcomes", r.HighMemories},
+		{"Memories behind the worst outcomes", r.LowMemories},
+	}
+}
+
+func writeReportTable(r *DecisionReport, w io.Writer) error {
+	fmt.Fprintf(w, "Decision report (%s)\n", r.GeneratedAt.Format("2006-01-02 15:04"))
+	if r.Since != nil {
+		fmt.Fprintf(w, "Since %s\n", r.Since.Format("2006-01-02"))
+	}
+	o := r.Overall
+	fmt.Fprintf(w, "%d decision(s), %d with outcomes, %d pending", o.Decisions, o.Recorded, o.Pending)
+	if o.Recorded > 0 {
+		fmt.Fprintf(w, ", mean outcome %.2f", o.Mean)
+	}
+	fmt.Fprintln(w)
+
+	for _, section := range reportSections(r) {
+		if len(section.stats) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  KEY\tDECISIONS\tRECORDED\tPENDING\tMEAN\tRANGE\tHISTOGRAM")
+		for _, s := range section.stats {
+			fmt.Fprintf(tw, "  %s\t%s\n", s.Key, strings.Join(statsCells(s), "\t"))
+		}
+		tw.Flush()
+	}
+
+	for _, list := range memorySections(r) {
+		if len(list.memories) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", list.title)
+		for _, m := range list.memories {
+			fmt.Fprintf(w, "  %.2f over %d decision(s), confidence %.2f  [%s] %s\n", m.MeanOutcome, m.Decisions, m.Confidence, shortID(m.ID), m.Content)
+		}
+	}
+
+	if len(r.Stale) > 0 {
+		fmt.Fprintf(w, "\nPending for %d+ days:\n", r.PendingDays)
+		for _, d := range r.Stale {
+			fmt.Fprintf(w, "  %3dd  [%s] %s (task %s, owner %s)\n", d.AgeDays, shortID(d.ID), d.DecisionText, d.TaskID, d.OwnerID)
+		}
+	}
+
+	return nil
+}
+
+// mdEscape keeps free text from breaking a markdown table row
+func mdEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func writeReportMarkdown(r *DecisionReport, w io.Writer) error {
+	fmt.Fprintf(w, "# Decision report\n\n")
+	fmt.Fprintf(w, "_Generated %s", r.GeneratedAt.Format("2006-01-02 15:04"))
+	if r.Since != nil {
+		fmt.Fprintf(w, ", covering decisions since %s", r.Since.Format("2006-01-02"))
+	}
+	fmt.Fprintf(w, "._\n\n")
+
+	o := r.Overall
+	fmt.Fprintf(w, "- **Decisions:** %d\n- **With outcomes:** %d\n- **Pending:** %d\n", o.Decisions, o.Recorded, o.Pending)
+	if o.Recorded > 0 {
+		fmt.Fprintf(w, "- **Mean outcome:** %.2f\n", o.Mean)
+	}
+
+	for _, section := range reportSections(r) {
+		if len(section.stats) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n## %s\n\n", section.title)
+		fmt.Fprintln(w, "| Key | Decisions | Recorded | Pending | Mean | Range | Histogram |")
+		fmt.Fprintln(w, "| --- | ---: | ---: | ---: | ---: | --- | --- |")
+		for _, s := range section.stats {
+			fmt.Fprintf(w, "| %s | %s |\n", mdEscape(s.Key), strings.Join(statsCells(s), " | "))
+		}
+	}
+
+	for _, list := range memorySections(r) {
+		if len(list.memories) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n## %s\n\n", list.title)
+		fmt.Fprintln(w, "| Memory | Mean outcome | Decisions | Confidence | Content |")
+		fmt.Fprintln(w, "| --- | ---: | ---: | ---: | --- |")
+		for _, m := range list.memories {
+			fmt.Fprintf(w, "| `%s` | %.2f | %d | %.2f | %s |\n", shortID(m.ID), m.MeanOutcome, m.Decisions, m.Confidence, mdEscape(m.Content))
+		}
+	}
+
+	if len(r.Stale) > 0 {
+		fmt.Fprintf(w, "\n## Pending for %d+ days\n\n", r.PendingDays)
+		fmt.Fprintln(w, "| Age | Decision | Task | Owner | Text |")
+		fmt.Fprintln(w, "| ---: | --- | --- | --- | --- |")
+		for _, d := range r.Stale {
+			fmt.Fprintf(w, "| %dd | `%s` | %s | %s | %s |\n", d.AgeDays, shortID(d.ID), mdEscape(d.TaskID), mdEscape(d.OwnerID), mdEscape(d.DecisionText))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// ReviewFilter narrows the under_review queue
+type ReviewFilter struct {
+	Source  string
+	TeamID  string
+	OwnerID string
+	Limit   int
+}
+
+// DefaultReviewer returns the identity recorded when no reviewer is given
+func DefaultReviewer() string {
+	if user := os.Getenv("AMI_REVIEWER"); user != "" {
+		return user
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "system"
+}
+
+// ListReviewQueue returns memories staged as under_review, oldest first
+func ListReviewQueue(filter ReviewFilter) ([]models.Memory, error) {
+	whereClauses := []string{fmt.Sprintf("status = '%s'", models.StatusUnderReview)}
+
+	if filter.Source != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("source = '%s'", strings.ReplaceAll(filter.Source, "'", "''")))
+	}
+	if filter.TeamID != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("team_id = '%s'", strings.ReplaceAll(filter.TeamID, "'", "''")))
+	}
+	if filter.OwnerID != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("owner_id = '%s'", strings.ReplaceAll(filter.OwnerID, "'", "''")))
+	}
+
+	limitClause := ""
+	if filter.Limit > 0 {
+		limitClause = fmt.Sprintf("LIMIT %d", filter.Limit)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id
+		FROM memories
+		WHERE %s
+		ORDER BY created_at ASC
+		%s
+	`, strings.Join(whereClauses, " AND "), limitClause)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list review queue: %w", err)
+	}
+
+	return parseMemoriesJSON(output)
+}
+
+// ApproveMemories marks staged memories as verified
+func ApproveMemories(ids []string, reviewer string) (int, error) {
+	return reviewMemories(ids, models.StatusVerified, reviewer, "Approve")
+}
+
+// RejectMemories deprecates staged memories so they never reach recall
+func RejectMemories(ids []string, reviewer string) (int, error) {
+	return reviewMemories(ids, models.StatusDeprecated, reviewer, "Reject")
+}
+
+// reviewMemories moves under_review memories to a new status in a single
+// Dolt commit, recording who reviewed them and when. Memories that are not
+// under review are left untouched; the number changed is returned.
+func reviewMemories(ids []string, status models.Status, reviewer string, verb string) (int, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if reviewer == "" {
+		reviewer = DefaultReviewer()
+	}
+
+	queue, err := reviewableIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+	if len(queue) == 0 {
+		return 0, nil
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE memories
+		SET status = '%s', reviewed_by = '%s', reviewed_at = NOW()
+		WHERE id IN (%s)
+	`, string(status), strings.ReplaceAll(reviewer, "'", "''"), sqlList(queue))
+
+	if _, err := db.ExecDoltSQL(query); err != nil {
+		return 0, fmt.Errorf("failed to update review status: %w", err)
+	}
+
+	commitMsg := fmt.Sprintf("%s %d memory(ies) (reviewer: %s)", verb, len(queue), reviewer)
+	if err := DoltCommit(commitMsg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+
+	return len(queue), nil
+}
+
+// EditReviewMemory rewrites a staged memory's content, optionally approving it
+// in the same commit
+func EditReviewMemory(id string, content string, reviewer string, approve bool) error {
+	if reviewer == "" {
+		reviewer = DefaultReviewer()
+	}
+
+	queue, err := reviewableIDs([]string{id})
+	if err != nil {
+		return err
+	}
+	if len(queue) == 0 {
+		return fmt.Errorf("memory %s is not under review", id)
+	}
+
+	status := models.StatusUnderReview
+	if approve {
+		status = models.StatusVerified
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE memories
+		SET content = '%s', content_hash = '%s', status = '%s', reviewed_by = '%s', reviewed_at = NOW()
+		WHERE id = '%s'
+	`, strings.ReplaceAll(content, "'", "''"), ContentHash(content), string(status), strings.ReplaceAll(reviewer, "'", "''"), id)
+
+	if _, err := db.ExecDoltSQL(query); err != nil {
+		return fmt.Errorf("failed to edit memory: %w", err)
+	}
+
+	commitMsg := fmt.Sprintf("Edit staged memory %s (reviewer: %s)", id, reviewer)
+	if err := DoltCommit(commitMsg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+
+	return nil
+}
+
+// reviewableIDs returns the subset of ids currently under review
+func reviewableIDs(ids []string) ([]string, error) {
+	query := fmt.Sprintf(`
+		SELECT id, status
+		FROM memories
+		WHERE id IN (%s) AND status = '%s'
+	`, sqlList(ids), models.StatusUnderReview)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check review status: %w", err)
+	}
+
+	memories, err := parseMemoriesJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := make([]string, 0, len(memories))
+	for _, m := range memories {
+		queue = append(queue, m.ID)
+	}
+	return queue, nil
+}
+
+// sqlList renders values as a quoted, comma-separated SQL list
+func sqlList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
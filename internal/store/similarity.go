@@ -0,0 +1,71 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// stopwords are dropped before lexical comparison
+var stopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "was": true, "were": true,
+	"this": true, "that": true, "with": true, "from": true, "into": true, "have": true,
+	"has": true, "had": true, "not": true, "but": true, "you": true, "our": true,
+	"use": true, "uses": true, "used": true, "using": true, "will": true, "can": true,
+	"all": true, "any": true, "its": true, "than": true, "then": true, "when": true,
+	"which": true, "what": true, "should": true, "would": true, "could": true, "been": true,
+	"also": true, "only": true, "each": true, "some": true, "such": true, "via": true,
+}
+
+// Tokenize splits content into lowercase terms, dropping stopwords and
+// anything shorter than three characters
+func Tokenize(content string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+	})
+
+	terms := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.Trim(f, "-_")
+		if len(f) < 3 || stopwords[f] {
+			continue
+		}
+		terms = append(terms, f)
+	}
+	return terms
+}
+
+// JaccardSimilarity returns |A ∩ B| / |A ∪ B| over the distinct terms of a and b
+func JaccardSimilarity(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	setA := make(map[string]bool, len(a))
+	for _, t := range a {
+		setA[t] = true
+	}
+	setB := make(map[string]bool, len(b))
+	for _, t := range b {
+		setB[t] = true
+	}
+
+	intersection := 0
+	for t := range setA {
+		if setB[t] {
+			intersection++
+		}
+	}
+	union := len(setA) + len(setB) - intersection
+	return float64(intersection) / float64(union)
+}
+
+// MemorySimilarity compares two memories by embedding when both have one,
+// and by term overlap otherwise. The second return value names the method.
+func MemorySimilarity(a, b models.Memory) (float64, string) {
+	if len(a.Embedding) > 0 && len(a.Embedding) == len(b.Embedding) {
+		return float64(CosineSimilarity(a.Embedding, b.Embedding)), "embedding"
+	}
+	return JaccardSimilarity(Tokenize(a.Content), Tokenize(b.Content)), "lexical"
+}
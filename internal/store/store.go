@@ -25,6 +25,7 @@ type CatchupOptions struct {
 	Limit    int
 	Category string
 	Since    string
+	Statuses []models.Status // Defaults to verified only
 }
 
 // RecallOptions specifies filters for memory recall
@@ -37,6 +38,7 @@ type RecallOptions struct {
 	TeamID     string
 	WithDecay  bool
 	Semantic   bool
+	Statuses   []models.Status // Defaults to verified only
 }
 
 // UpdateParams specifies fields to update on a memory
@@ -48,6 +50,30 @@ type UpdateParams struct {
 	Priority *float64
 	Source   *string
 	Tags     []string
+	TTL      *time.Duration // Zero clears the expiry; positive resets it to now + TTL
+}
+
+// AddParams specifies the fields of a new memory
+type AddParams struct {
+	Content     string
+	OwnerID     string
+	Category    models.Category
+	Priority    float64
+	Tags        []string
+	Source      string
+	TeamID      string
+	Status      models.Status
+	TTL         *time.Duration // nil uses the category default; zero never expires
+	Supersedes  string         // ID of an older memory this one replaces
+	OnDuplicate DuplicatePolicy
+	Search      DuplicateSearch // Narrows the duplicate check
+}
+
+// AddResult reports what AddMemoryWithParams did
+type AddResult struct {
+	Memory    *models.Memory  `json:"memory"`
+	Action    string          `json:"action"` // "added", "merged", "linked" or "rejected"
+	Duplicate *DuplicateMatch `json:"duplicate,omitempty"`
 }
 
 // DoltCommit is a wrapper for db.DoltCommit
@@ -55,6 +81,42 @@ func DoltCommit(message string) error {
 	return db.DoltCommit(message)
 }
 
+// doltWorkingSetDirty reports whether the working set has uncommitted changes
+func doltWorkingSetDirty() (bool, error) {
+	output, err := ExecDoltSQLJSON("SELECT table_name FROM dolt_status")
+	if err != nil {
+		return false, fmt.Errorf("failed to read working set status: %w", err)
+	}
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return false, err
+	}
+	return len(result.Rows) > 0, nil
+}
+
+// writeOrRollBack runs write, which leaves its changes uncommitted, on a
+// clean working set. If write fails the working set is reset, so a partial
+// batch never rides along with the next commit.
+func writeOrRollBack(write func() error) error {
+	dirty, err := doltWorkingSetDirty()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("working set has uncommitted changes; commit or discard them first")
+	}
+
+	if err := write(); err != nil {
+		if _, resetErr := db.RunDolt("reset", "--hard"); resetErr != nil {
+			return fmt.Errorf("%w (and failed to roll back: %v)", err, resetErr)
+		}
+		return err
+	}
+	return nil
+}
+
 // ExecDoltSQLJSON executes a SQL query via dolt CLI and returns JSON output
 func ExecDoltSQLJSON(query string) (string, error) {
 	repoPath, err := db.GetRepoPath()
@@ -73,12 +135,106 @@ func ExecDoltSQLJSON(query string) (string, error) {
 	return string(output), nil
 }
 
-// AddMemory adds a new memory to the database and creates a Dolt commit
+// statusClause restricts a query to the given statuses, defaulting to
+// verified only. Rows written before status existed count as verified.
+func statusClause(statuses []models.Status) string {
+	if len(statuses) == 0 {
+		statuses = []models.Status{models.StatusVerified}
+	}
+	values := make([]string, len(statuses))
+	for i, st := range statuses {
+		values[i] = string(st)
+	}
+	return fmt.Sprintf("COALESCE(status, 'verified') IN (%s)", sqlList(values))
+}
+
+// AddMemory adds a new memory to the database and creates a Dolt commit.
+// Duplicates are not checked; use AddMemoryWithParams to pick a policy.
 func AddMemory(content string, ownerID string, category models.Category, priority float64, tags []string, source string, teamID string) (*models.Memory, error) {
+	result, err := AddMemoryWithParams(AddParams{
+		Content:     content,
+		OwnerID:     ownerID,
+		Category:    category,
+		Priority:    priority,
+		Tags:        tags,
+		Source:      source,
+		TeamID:      teamID,
+		OnDuplicate: DuplicateAllow,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Memory, nil
+}
+
+// AddMemoryWithParams adds a new memory, first checking it against existing
+// memories in the same team and applying the duplicate policy on a match
+func AddMemoryWithParams(params AddParams) (*AddResult, error) {
+	if params.Supersedes != "" {
+		if _, err := GetMemoryByID(params.Supersedes); err != nil {
+			return nil, err
+		}
+		// A revision is always a near-duplicate of what it replaces, and the
+		// new content must survive: never match the superseded memory, and
+		// link to other duplicates rather than merging into them
+		params.Search.ExcludeIDs = append(params.Search.ExcludeIDs, params.Supersedes)
+		if params.OnDuplicate == "" || params.OnDuplicate == DuplicateMerge {
+			params.OnDuplicate = DuplicateLink
+		}
+	}
+
+	result, err := insertMemory(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Supersedes != "" && result.Action != "rejected" {
+		if err := supersede(params.Supersedes, result.Memory.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	var commitMsg string
+	switch result.Action {
+	case "rejected":
+		return result, nil
+	case "merged":
+		commitMsg = fmt.Sprintf("Merge duplicate into memory %s", result.Memory.ID)
+	default:
+		// Create Dolt commit for versioning
+		excerpt := params.Content
+		if len(excerpt) > 50 {
+			excerpt = excerpt[:50] + "..."
+		}
+		commitMsg = fmt.Sprintf("Add memory: %s", excerpt)
+	}
+	if params.Supersedes != "" {
+		commitMsg += fmt.Sprintf(" (supersedes %s)", params.Supersedes)
+	}
+
+	if err := DoltCommit(commitMsg); err != nil {
+		// Log warning but don't fail the memory add
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+
+	return result, nil
+}
+
+// insertMemory does the work of AddMemoryWithParams without committing, so
+// callers that write several rows can bundle them into one Dolt commit
+func insertMemory(params AddParams) (*AddResult, error) {
 	// Generate UUID
 	id := uuid.New().String()
 	now := time.Now().Format("2006-01-02 15:04:05")
 
+	content := params.Content
+	ownerID := params.OwnerID
+	teamID := params.TeamID
+	tags, err := CanonicalTags(params.Tags)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set default owner if empty
 	if ownerID == "" {
 		ownerID = "system"
@@ -89,7 +245,35 @@ func AddMemory(content string, ownerID string, category models.Category, priorit
 		teamID = "system"
 	}
 
+	policy := params.OnDuplicate
+	if policy == "" {
+		policy = DuplicateMerge
+	}
+
+	status := params.Status
+	if status == "" {
+		status = models.StatusVerified
+	}
+
+	// Working memories are task-scoped scratch state and expire by default
+	var expiresAt *time.Time
+	ttl := time.Duration(0)
+	if params.TTL != nil {
+		ttl = *params.TTL
+	} else if params.Category == models.CategoryWorking {
+		ttl = DefaultWorkingTTL()
+	}
+	expiresSQL := "NULL"
+	if ttl > 0 {
+		t := time.Now().Add(ttl)
+		expiresAt = &t
+		expiresSQL = fmt.Sprintf("'%s'", t.Format("2006-01-02 15:04:05"))
+	}
+
 	// Convert tags to JSON for SQL
+	if tags == nil {
+		tags = []string{}
+	}
 	tagsJSON, err := json.Marshal(tags)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal tags: %w", err)
@@ -97,61 +281,93 @@ func AddMemory(content string, ownerID string, category models.Category, priorit
 
 	// Escape single quotes in content
 	escapedContent := strings.ReplaceAll(content, "'", "''")
-	escapedSource := strings.ReplaceAll(source, "'", "''")
+	escapedSource := strings.ReplaceAll(params.Source, "'", "''")
 
 	// 1. Calculate embedding if enabled (v0.4.0)
+	var vector []float32
 	embeddingHex := "NULL"
 	if os.Getenv("OPENAI_API_KEY") != "" {
-		vector, err := GetEmbedding(content)
+		vector, err = GetEmbedding(content)
 		if err == nil {
 			binaryData := Float32ToBinary(vector)
 			embeddingHex = fmt.Sprintf("X'%x'", binaryData)
+		} else {
+			vector = nil
+		}
+	}
+
+	// 2. Check for near-duplicates before writing anything
+	var match *DuplicateMatch
+	if policy != DuplicateAllow {
+		match, err = FindDuplicate(content, teamID, vector, params.Search)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if match != nil {
+		// A reviewed memory never disappears into one still awaiting review
+		if policy == DuplicateMerge && match.Memory.Status == models.StatusUnderReview && status != models.StatusUnderReview {
+			policy = DuplicateLink
+		}
+		switch policy {
+		case DuplicateReject:
+			existing := match.Memory
+			return &AddResult{Memory: &existing, Action: "rejected", Duplicate: match}, nil
+		case DuplicateMerge:
+			merged, err := mergeDuplicate(match.Memory, params.Priority, tags, expiresAt)
+			if err != nil {
+				return nil, err
+			}
+			return &AddResult{Memory: merged, Action: "merged", Duplicate: match}, nil
 		}
 	}
 
-	// 2. Insert memory using dolt CLI
+	// 3. Insert memory using dolt CLI
 	query := fmt.Sprintf(`
-		INSERT INTO memories (id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, embedding, team_id)
-		VALUES ('%s', '%s', '%s', '%s', %f, '%s', '%s', 0, '%s', '%s', %s, '%s')
-	`, id, escapedContent, ownerID, string(category), priority, now, now, escapedSource, string(tagsJSON), embeddingHex, teamID)
+		INSERT INTO memories (id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, embedding, status, team_id, content_hash, expires_at)
+		VALUES ('%s', '%s', '%s', '%s', %f, '%s', '%s', 0, '%s', '%s', %s, '%s', '%s', '%s', %s)
+	`, id, escapedContent, ownerID, string(params.Category), params.Priority, now, now, escapedSource, string(tagsJSON), embeddingHex, string(status), teamID, ContentHash(content), expiresSQL)
 
 	_, err = db.ExecDoltSQL(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert memory: %w", err)
 	}
 
-	// Create Dolt commit for versioning
-	excerpt := content
-	if len(excerpt) > 50 {
-		excerpt = excerpt[:50] + "..."
-	}
-	commitMsg := fmt.Sprintf("Add memory: %s", excerpt)
-
-	if err := DoltCommit(commitMsg); err != nil {
-		// Log warning but don't fail the memory add
-		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	action := "added"
+	if match != nil && policy == DuplicateLink {
+		if err := insertLink(id, match.Memory.ID, "duplicate_of"); err != nil {
+			return nil, fmt.Errorf("failed to link duplicate: %w", err)
+		}
+		action = "linked"
 	}
 
 	// Return the created memory
 	createdTime, _ := time.Parse("2006-01-02 15:04:05", now)
-	return &models.Memory{
-		ID:          id,
-		Content:     content,
-		OwnerID:     ownerID,
-		Category:    category,
-		Priority:    priority,
-		CreatedAt:   createdTime,
-		AccessedAt:  createdTime,
-		AccessCount: 0,
-		Source:      source,
-		Tags:        models.Tags(tags),
-		TeamID:      teamID,
+	return &AddResult{
+		Memory: &models.Memory{
+			ID:          id,
+			Content:     content,
+			OwnerID:     ownerID,
+			Category:    params.Category,
+			Priority:    params.Priority,
+			CreatedAt:   createdTime,
+			AccessedAt:  createdTime,
+			AccessCount: 0,
+			Source:      params.Source,
+			Tags:        models.Tags(tags),
+			Status:      status,
+			TeamID:      teamID,
+			ExpiresAt:   expiresAt,
+		},
+		Action:    action,
+		Duplicate: match,
 	}, nil
 }
 
 // CatchupMemories returns the most recent memories
 func CatchupMemories(opts CatchupOptions) ([]models.Memory, error) {
-	whereClauses := []string{}
+	whereClauses := []string{visibleClause(opts.Statuses)}
 
 	if opts.Category != "" {
 		cat := models.Category(opts.Category)
@@ -170,7 +386,7 @@ func CatchupMemories(opts CatchupOptions) ([]models.Memory, error) {
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, content, category, priority, created_at, accessed_at, access_count, source, tags
+		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, expires_at
 		FROM memories
 		%s
 		ORDER BY created_at DESC
@@ -187,95 +403,33 @@ func CatchupMemories(opts CatchupOptions) ([]models.Memory, error) {
 
 // RecallMemories performs a basic text search on memories with optional filters
 func RecallMemories(opts RecallOptions) ([]models.Memory, error) {
-	// 1. Build WHERE clause
-	whereClauses := []string{}
-
-	// Text search
-	if opts.Query != "" {
-		escapedQuery := strings.ReplaceAll(opts.Query, "'", "''")
-		whereClauses = append(whereClauses, fmt.Sprintf("content LIKE '%%%s%%'", escapedQuery))
-	}
-
-	// Category filter
-	if opts.Category != "" {
-		cat := models.Category(opts.Category)
-		if cat.IsValid() {
-			whereClauses = append(whereClauses, fmt.Sprintf("category = '%s'", string(cat)))
-		}
-	}
-
-	// Owner filter
-	if opts.OwnerID != "" {
-		whereClauses = append(whereClauses, fmt.Sprintf("owner_id = '%s'", opts.OwnerID))
-	}
-
-	// Team filter
-	if opts.TeamID != "" {
-		whereClauses = append(whereClauses, fmt.Sprintf("team_id = '%s'", opts.TeamID))
+	whereClause, err := recallWhereClause(opts)
+	if err != nil {
+		return nil, err
 	}
 
-	// Tags filter - check JSON_CONTAINS
-	if len(opts.Tags) > 0 {
-		for _, tag := range opts.Tags {
-			escapedTag := strings.ReplaceAll(tag, "'", "''")
-			whereClauses = append(whereClauses, fmt.Sprintf("JSON_CONTAINS(tags, '\"%s\"')", escapedTag))
-		}
-	}
+	where := strings.TrimPrefix(whereClause, "WHERE ")
 
-	// Combine WHERE clauses
-	whereClause := ""
-	if len(whereClauses) > 0 {
-		whereClause = "WHERE " + strings.Join(whereClauses, " AND ")
-	}
-
-	// Build query
-	var searchQuery string
 	if opts.Semantic {
 		// Fetch all memories with embeddings for in-memory ranking
-		searchQuery = fmt.Sprintf(`
-			SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, embedding, embedding_cached, status, team_id
+		searchQuery := fmt.Sprintf(`
+			SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, embedding, embedding_cached, status, team_id, expires_at
 			FROM memories
 			%s
 		`, whereClause)
-	} else if opts.WithDecay {
-		// Use logarithmic decay scoring:
-		// Score = (Priority * (AccessCount + 1)) / (log10(TimeDelta + 10) * CategoryDecay)
-		searchQuery = fmt.Sprintf(`
-			SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id,
-			(priority * (access_count + 1)) / (LOG10(TIMESTAMPDIFF(SECOND, accessed_at, NOW()) + 10) * 
-			CASE 
-				WHEN category = 'core' THEN 0.5 
-				WHEN category = 'semantic' THEN 1.0 
-				WHEN category = 'episodic' THEN 2.0 
-				ELSE 1.5 
-			END) as recall_score
-			FROM memories
-			%s
-			ORDER BY recall_score DESC
-			LIMIT %d
-		`, whereClause, opts.Limit)
-	} else {
-		searchQuery = fmt.Sprintf(`
-			SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id
-			FROM memories
-			%s
-			ORDER BY priority DESC, accessed_at DESC
-			LIMIT %d
-		`, whereClause, opts.Limit)
-	}
-
-	output, err := ExecDoltSQLJSON(searchQuery)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search memories: %w", err)
-	}
 
-	// Parse JSON output
-	memories, err := parseMemoriesJSON(output)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse memories: %w", err)
-	}
+		output, err := ExecDoltSQLJSON(searchQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search memories: %w", err)
+		}
+		memories, err := parseMemoriesJSON(output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse memories: %w", err)
+		}
+		if opts.Query == "" {
+			return collapseSupersession(memories, where)
+		}
 
-	if opts.Semantic && opts.Query != "" {
 		// 1. Get embedding for the query
 		queryVector, err := GetEmbedding(opts.Query)
 		if err != nil {
@@ -300,15 +454,127 @@ func RecallMemories(opts RecallOptions) ([]models.Memory, error) {
 			return ranked[i].score > ranked[j].score
 		})
 
-		// Convert back to []models.Memory and apply limit
-		finalMemories := make([]models.Memory, 0, len(ranked))
-		for i := 0; i < len(ranked) && i < opts.Limit; i++ {
-			finalMemories = append(finalMemories, ranked[i].Memory)
+		// Convert back to []models.Memory, collapse and then apply the limit
+		rankedMemories := make([]models.Memory, 0, len(ranked))
+		for _, r := range ranked {
+			rankedMemories = append(rankedMemories, r.Memory)
 		}
-		return finalMemories, nil
+		collapsed, err := collapseSupersession(rankedMemories, where)
+		if err != nil {
+			return nil, err
+		}
+		if len(collapsed) > opts.Limit {
+			collapsed = collapsed[:opts.Limit]
+		}
+		return collapsed, nil
+	}
+
+	return fetchCollapsed(opts.Limit, where, func(limit int) ([]models.Memory, error) {
+		var searchQuery string
+		if opts.WithDecay {
+			// Use logarithmic decay scoring:
+			// Score = (Priority * (AccessCount + 1)) / (log10(TimeDelta + 10) * CategoryDecay)
+			searchQuery = fmt.Sprintf(`
+				SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, expires_at,
+				(priority * (access_count + 1)) / (LOG10(TIMESTAMPDIFF(SECOND, accessed_at, NOW()) + 10) * 
+				CASE 
+					WHEN category = 'core' THEN 0.5 
+					WHEN category = 'semantic' THEN 1.0 
+					WHEN category = 'episodic' THEN 2.0 
+					ELSE 1.5 
+				END) as recall_score
+				FROM memories
+				%s
+				ORDER BY recall_score DESC
+				LIMIT %d
+			`, whereClause, limit)
+		} else {
+			searchQuery = fmt.Sprintf(`
+				SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, expires_at
+				FROM memories
+				%s
+				ORDER BY priority DESC, accessed_at DESC
+				LIMIT %d
+			`, whereClause, limit)
+		}
+
+		output, err := ExecDoltSQLJSON(searchQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search memories: %w", err)
+		}
+
+		// Parse JSON output
+		memories, err := parseMemoriesJSON(output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse memories: %w", err)
+		}
+		return memories, nil
+	})
+}
+
+// DecayScore computes the recall --decay score for a memory in Go, matching
+// the SQL formula: (Priority * (AccessCount + 1)) / (log10(TimeDelta + 10) * CategoryDecay)
+func DecayScore(m models.Memory, now time.Time) float64 {
+	categoryDecay := 1.5
+	switch m.Category {
+	case models.CategoryCore:
+		categoryDecay = 0.5
+	case models.CategorySemantic:
+		categoryDecay = 1.0
+	case models.CategoryEpisodic:
+		categoryDecay = 2.0
 	}
 
-	return memories, nil
+	elapsed := now.Sub(m.AccessedAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	return (m.Priority * float64(m.AccessCount+1)) / (math.Log10(elapsed+10) * categoryDecay)
+}
+
+// recallWhereClause builds the WHERE clause shared by recall and graph
+// queries from the recall filters
+func recallWhereClause(opts RecallOptions) (string, error) {
+	whereClauses := []string{visibleClause(opts.Statuses)}
+
+	// Text search
+	if opts.Query != "" {
+		escapedQuery := strings.ReplaceAll(opts.Query, "'", "''")
+		whereClauses = append(whereClauses, fmt.Sprintf("content LIKE '%%%s%%'", escapedQuery))
+	}
+
+	// Category filter
+	if opts.Category != "" {
+		cat := models.Category(opts.Category)
+		if cat.IsValid() {
+			whereClauses = append(whereClauses, fmt.Sprintf("category = '%s'", string(cat)))
+		}
+	}
+
+	// Owner filter
+	if opts.OwnerID != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("owner_id = '%s'", opts.OwnerID))
+	}
+
+	// Team filter
+	if opts.TeamID != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("team_id = '%s'", opts.TeamID))
+	}
+
+	// Tags filter - a tag also matches its aliases and its children
+	if len(opts.Tags) > 0 {
+		aliases, err := LoadTagAliases()
+		if err != nil {
+			return "", err
+		}
+		for _, tag := range opts.Tags {
+			whereClauses = append(whereClauses, tagFilterClause(tag, aliases))
+		}
+	}
+
+	// Combine WHERE clauses
+	return "WHERE " + strings.Join(whereClauses, " AND "), nil
 }
 
 // MemoryHistory represents a version of a memory in history
@@ -368,20 +634,41 @@ func GetMemoryHistory(id string) ([]MemoryHistory, error) {
 	return history, nil
 }
 
-// LinkMemories creates a link between two memories
+// LinkMemories creates a link between two memories. The relation must be
+// registered; inverse names are stored in canonical form.
 func LinkMemories(fromID, toID, relation string) error {
+	fromID, toID, relation, err := normalizeLink(fromID, toID, relation)
+	if err != nil {
+		return err
+	}
+	if err := writeLink(fromID, toID, relation); err != nil {
+		return err
+	}
+
+	commitMsg := fmt.Sprintf("Link memory %s to %s (%s)", fromID, toID, relation)
+	return DoltCommit(commitMsg)
+}
+
+// insertLink validates and writes a memory_links row without committing, so
+// callers can bundle it into a larger change
+func insertLink(fromID, toID, relation string) error {
+	fromID, toID, relation, err := normalizeLink(fromID, toID, relation)
+	if err != nil {
+		return err
+	}
+	return writeLink(fromID, toID, relation)
+}
+
+// writeLink writes an already-normalized link
+func writeLink(fromID, toID, relation string) error {
 	query := fmt.Sprintf(`
 		INSERT INTO memory_links (from_id, to_id, relation)
 		VALUES ('%s', '%s', '%s')
 		ON DUPLICATE KEY UPDATE relation = VALUES(relation)
 	`, fromID, toID, relation)
 
-	if _, err := db.ExecDoltSQL(query); err != nil {
-		return err
-	}
-
-	commitMsg := fmt.Sprintf("Link memory %s to %s (%s)", fromID, toID, relation)
-	return DoltCommit(commitMsg)
+	_, err := db.ExecDoltSQL(query)
+	return err
 }
 
 // GetMemoryLinks returns all links for a specific memory
@@ -417,21 +704,24 @@ func GetMemoryLinks(id string) ([]map[string]string, error) {
 }
 
 // GetKeystoneMemories returns high-priority and high-access memories
-func GetKeystoneMemories(limit int) ([]models.Memory, error) {
-	// Formula: (Priority * 2) + (AccessCount / 10)
-	query := fmt.Sprintf(`
-		SELECT id, content, category, priority, created_at, accessed_at, access_count, source, tags
-		FROM memories
-		ORDER BY (priority * 2) + (access_count / 10.0) DESC
-		LIMIT %d
-	`, limit)
-
-	output, err := ExecDoltSQLJSON(query)
-	if err != nil {
-		return nil, err
-	}
+func GetKeystoneMemories(limit int, statuses []models.Status) ([]models.Memory, error) {
+	where := visibleClause(statuses)
+	return fetchCollapsed(limit, where, func(limit int) ([]models.Memory, error) {
+		// Formula: (Priority * 2) + (AccessCount / 10)
+		query := fmt.Sprintf(`
+			SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, expires_at
+			FROM memories
+			WHERE %s
+			ORDER BY (priority * 2) + (access_count / 10.0) DESC
+			LIMIT %d
+		`, where, limit)
 
-	return parseMemoriesJSON(output)
+		output, err := ExecDoltSQLJSON(query)
+		if err != nil {
+			return nil, err
+		}
+		return parseMemoriesJSON(output)
+	})
 }
 
 // CountTokens counts tokens in a string
@@ -447,12 +737,14 @@ func CountTokens(text string) int {
 	return len(token)
 }
 
-// GetContextMemories returns memories optimized for prompt context
-func GetContextMemories(task string, limit int, tokenBudget int) ([]models.Memory, error) {
+// GetContextMemories returns memories optimized for prompt context.
+// Only memories in the given statuses are considered (verified by default).
+func GetContextMemories(task string, limit int, tokenBudget int, statuses []models.Status) ([]models.Memory, error) {
 	// 1. Get high-priority core facts first
 	coreOpts := RecallOptions{
 		Category: "core",
 		Limit:    10,
+		Statuses: statuses,
 	}
 	coreMemories, _ := RecallMemories(coreOpts)
 
@@ -464,6 +756,7 @@ func GetContextMemories(task string, limit int, tokenBudget int) ([]models.Memor
 			Limit:     limit,
 			WithDecay: true,
 			Semantic:  true,
+			Statuses:  statuses,
 		}
 		taskMemories, _ = RecallMemories(taskOpts)
 	}
@@ -616,6 +909,7 @@ func UpdateMemory(params UpdateParams) error {
 	if params.Content != nil {
 		escapedContent := strings.ReplaceAll(*params.Content, "'", "''")
 		setClauses = append(setClauses, fmt.Sprintf("content = '%s'", escapedContent))
+		setClauses = append(setClauses, fmt.Sprintf("content_hash = '%s'", ContentHash(*params.Content)))
 	}
 
 	if params.OwnerID != nil {
@@ -636,13 +930,26 @@ func UpdateMemory(params UpdateParams) error {
 	}
 
 	if params.Tags != nil {
-		tagsJSON, err := json.Marshal(params.Tags)
+		tags, err := CanonicalTags(params.Tags)
+		if err != nil {
+			return err
+		}
+		tagsJSON, err := json.Marshal(tags)
 		if err != nil {
 			return fmt.Errorf("failed to marshal tags: %w", err)
 		}
 		setClauses = append(setClauses, fmt.Sprintf("tags = '%s'", string(tagsJSON)))
 	}
 
+	if params.TTL != nil {
+		if *params.TTL > 0 {
+			expiresAt := time.Now().Add(*params.TTL).Format("2006-01-02 15:04:05")
+			setClauses = append(setClauses, fmt.Sprintf("expires_at = '%s'", expiresAt))
+		} else {
+			setClauses = append(setClauses, "expires_at = NULL")
+		}
+	}
+
 	// Update accessed_at to refresh timestamp
 	now := time.Now().Format("2006-01-02 15:04:05")
 	setClauses = append(setClauses, fmt.Sprintf("accessed_at = '%s'", now))
@@ -707,9 +1014,9 @@ func RollbackMemory(id string, commitHash string) error {
 	// 2. Update the current memories table
 	updateQuery := fmt.Sprintf(`
 		UPDATE memories
-		SET content = '%s', category = '%s', priority = %f, source = '%s', tags = '%s', accessed_at = NOW()
+		SET content = '%s', content_hash = '%s', category = '%s', priority = %f, source = '%s', tags = '%s', accessed_at = NOW()
 		WHERE id = '%s'
-	`, strings.ReplaceAll(content, "'", "''"), category, priority, strings.ReplaceAll(source, "'", "''"), tagsJSON, id)
+	`, strings.ReplaceAll(content, "'", "''"), ContentHash(content), category, priority, strings.ReplaceAll(source, "'", "''"), tagsJSON, id)
 
 	if _, err := db.ExecDoltSQL(updateQuery); err != nil {
 		return err
@@ -784,14 +1091,18 @@ func ListTags() ([]string, error) {
 	return uniqueTags, nil
 }
 
-// GetMemoryStats returns analytics about the memory database
-func GetMemoryStats() (map[string]interface{}, error) {
+// GetMemoryStats returns analytics about the memories with the given
+// statuses (verified by default), leaving out expired ones
+func GetMemoryStats(statuses []models.Status) (map[string]interface{}, error) {
+	where := visibleClause(statuses)
+
 	// Category distribution
-	distQuery := `
+	distQuery := fmt.Sprintf(`
 		SELECT category, COUNT(*) as count 
 		FROM memories 
+		WHERE %s
 		GROUP BY category
-	`
+	`, where)
 	output, err := ExecDoltSQLJSON(distQuery)
 	if err != nil {
 		return nil, err
@@ -812,7 +1123,7 @@ func GetMemoryStats() (map[string]interface{}, error) {
 	}
 
 	// Average priority and decay
-	metricsQuery := `
+	metricsQuery := fmt.Sprintf(`
 		SELECT 
 			AVG(priority) as avg_priority,
 			AVG(access_count) as avg_access,
@@ -827,8 +1138,9 @@ func GetMemoryStats() (map[string]interface{}, error) {
 				ELSE 1.5 
 			END) as recall_score
 			FROM memories
+			WHERE %s
 		) as metrics
-	`
+	`, where)
 	output, err = ExecDoltSQLJSON(metricsQuery)
 	if err != nil {
 		return nil, err
@@ -860,9 +1172,10 @@ func GetMemoryStats() (map[string]interface{}, error) {
 	}, nil
 }
 
-// GetMemoryCount returns total number of memories
-func GetMemoryCount() (int, error) {
-	output, err := ExecDoltSQLJSON("SELECT COUNT(*) as count FROM memories")
+// GetMemoryCount returns the number of memories with the given statuses
+// (verified by default), leaving out expired ones
+func GetMemoryCount(statuses []models.Status) (int, error) {
+	output, err := ExecDoltSQLJSON("SELECT COUNT(*) as count FROM memories WHERE " + visibleClause(statuses))
 	if err != nil {
 		return 0, fmt.Errorf("failed to count memories: %w", err)
 	}
@@ -913,6 +1226,13 @@ func parseMemoriesJSON(output string) ([]models.Memory, error) {
 		m.EmbeddingCached = models.AsInt(row["embedding_cached"]) == 1
 		m.Status = models.Status(models.AsString(row["status"]))
 		m.TeamID = models.AsString(row["team_id"])
+		m.ReviewedBy = models.AsString(row["reviewed_by"])
+		if reviewedAt := models.AsTime(row["reviewed_at"]); !reviewedAt.IsZero() {
+			m.ReviewedAt = &reviewedAt
+		}
+		if expiresAt := models.AsTime(row["expires_at"]); !expiresAt.IsZero() {
+			m.ExpiresAt = &expiresAt
+		}
 
 		// Parse embedding if present
 		if row["embedding"] != nil {
@@ -963,7 +1283,7 @@ func FindAutoPromotionCandidates(minAccessCount int, minOutcome float64) ([]mode
 // GetMemoryByID retrieves a specific memory by ID
 func GetMemoryByID(id string) (*models.Memory, error) {
 	query := fmt.Sprintf(`
-		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id
+		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, expires_at
 		FROM memories
 		WHERE id = '%s'
 	`, id)
@@ -1009,9 +1329,9 @@ func UpdateMemoryContent(id string, content string) error {
 	escapedContent := strings.ReplaceAll(content, "'", "''")
 	query := fmt.Sprintf(`
 		UPDATE memories
-		SET content = '%s'
+		SET content = '%s', content_hash = '%s'
 		WHERE id = '%s'
-	`, escapedContent, id)
+	`, escapedContent, ContentHash(content), id)
 
 	_, err := ExecDoltSQLJSON(query)
 	if err != nil {
@@ -0,0 +1,242 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// RelationSupersedes links a newer memory (from_id) to the older memory it
+// replaces (to_id)
+const RelationSupersedes = "supersedes"
+
+// supersessionGraph holds every supersedes link in both directions
+type supersessionGraph struct {
+	newer map[string]string   // old ID -> ID of the memory that replaced it
+	older map[string][]string // new ID -> IDs it replaced
+}
+
+// loadSupersessionGraph reads all supersedes links
+func loadSupersessionGraph() (*supersessionGraph, error) {
+	query := fmt.Sprintf(`
+		SELECT l.from_id, l.to_id
+		FROM memory_links l
+		JOIN memories m ON m.id = l.from_id
+		WHERE l.relation = '%s'
+		ORDER BY m.created_at ASC
+	`, RelationSupersedes)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load supersession links: %w", err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, err
+	}
+
+	g := &supersessionGraph{
+		newer: make(map[string]string),
+		older: make(map[string][]string),
+	}
+	for _, row := range result.Rows {
+		from := models.AsString(row["from_id"])
+		to := models.AsString(row["to_id"])
+		// The most recent replacement wins if a memory was superseded twice
+		g.newer[to] = from
+		g.older[from] = append(g.older[from], to)
+	}
+
+	return g, nil
+}
+
+// head follows the chain forward from id to its newest member
+func (g *supersessionGraph) head(id string) string {
+	seen := map[string]bool{id: true}
+	for {
+		next, ok := g.newer[id]
+		if !ok || seen[next] {
+			return id
+		}
+		seen[next] = true
+		id = next
+	}
+}
+
+// SupersedeMemory records that newID replaces oldID: a supersedes link is
+// added and the old memory is deprecated, in one Dolt commit
+func SupersedeMemory(oldID, newID string) error {
+	if err := supersede(oldID, newID); err != nil {
+		return err
+	}
+
+	commitMsg := fmt.Sprintf("Supersede memory %s with %s", oldID, newID)
+	if err := DoltCommit(commitMsg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+
+	return nil
+}
+
+// supersede writes the link and deprecation without committing
+func supersede(oldID, newID string) error {
+	// insertLink checks both memories exist and rejects supersession cycles
+	if err := insertLink(newID, oldID, RelationSupersedes); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE memories SET status = '%s' WHERE id = '%s'", models.StatusDeprecated, oldID)
+	if _, err := db.ExecDoltSQL(query); err != nil {
+		return fmt.Errorf("failed to deprecate memory: %w", err)
+	}
+
+	return nil
+}
+
+// GetLineage returns the whole supersession chain that id belongs to, oldest
+// first. A memory that was never superseded has a lineage of one.
+func GetLineage(id string) ([]models.Memory, error) {
+	g, err := loadSupersessionGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk back from the head so branches merged into it are included
+	var ids []string
+	seen := make(map[string]bool)
+	var walk func(string)
+	walk = func(cur string) {
+		if seen[cur] {
+			return
+		}
+		seen[cur] = true
+		for _, prev := range g.older[cur] {
+			walk(prev)
+		}
+		ids = append(ids, cur)
+	}
+	walk(g.head(id))
+
+	lineage := make([]models.Memory, 0, len(ids))
+	for _, lid := range ids {
+		m, err := GetMemoryByID(lid)
+		if err != nil {
+			return nil, err
+		}
+		lineage = append(lineage, *m)
+	}
+
+	return lineage, nil
+}
+
+// collapseSupersession replaces each memory with the newest member of its
+// chain that still matches the caller's filters (where, a SQL condition on
+// memories), dropping repeats, so a read never returns two versions of the
+// same fact
+func collapseSupersession(memories []models.Memory, where string) ([]models.Memory, error) {
+	if len(memories) == 0 {
+		return memories, nil
+	}
+
+	g, err := loadSupersessionGraph()
+	if err != nil {
+		return nil, err
+	}
+	if len(g.newer) == 0 {
+		return memories, nil
+	}
+
+	// The input already matched where; newer chain members are checked
+	// against it in one query
+	eligible := make(map[string]models.Memory, len(memories))
+	for _, m := range memories {
+		eligible[m.ID] = m
+	}
+	var newer []string
+	for _, m := range memories {
+		seen := map[string]bool{m.ID: true}
+		for id := m.ID; ; {
+			next, ok := g.newer[id]
+			if !ok || seen[next] {
+				break
+			}
+			seen[next] = true
+			id = next
+			if _, ok := eligible[next]; !ok {
+				newer = append(newer, next)
+			}
+		}
+	}
+	if len(newer) > 0 {
+		query := fmt.Sprintf(`
+			SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, status, team_id, expires_at
+			FROM memories
+			WHERE (%s) AND id IN (%s)
+		`, where, sqlList(uniqueStrings(newer)))
+		output, err := ExecDoltSQLJSON(query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load superseding memories: %w", err)
+		}
+		replacements, err := parseMemoriesJSON(output)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range replacements {
+			eligible[m.ID] = m
+		}
+	}
+
+	collapsed := make([]models.Memory, 0, len(memories))
+	emitted := make(map[string]bool)
+	for _, m := range memories {
+		current := m
+		seen := map[string]bool{m.ID: true}
+		for id := m.ID; ; {
+			next, ok := g.newer[id]
+			if !ok || seen[next] {
+				break
+			}
+			seen[next] = true
+			id = next
+			if candidate, ok := eligible[next]; ok {
+				current = candidate
+			}
+		}
+
+		if !emitted[current.ID] {
+			emitted[current.ID] = true
+			collapsed = append(collapsed, current)
+		}
+	}
+
+	return collapsed, nil
+}
+
+// fetchCollapsed runs fetch with a growing limit until collapsing
+// supersession chains still leaves limit memories, or the store runs out
+func fetchCollapsed(limit int, where string, fetch func(limit int) ([]models.Memory, error)) ([]models.Memory, error) {
+	fetchLimit := limit
+	for {
+		memories, err := fetch(fetchLimit)
+		if err != nil {
+			return nil, err
+		}
+		collapsed, err := collapseSupersession(memories, where)
+		if err != nil {
+			return nil, err
+		}
+		if len(collapsed) >= limit || len(memories) < fetchLimit {
+			if len(collapsed) > limit {
+				collapsed = collapsed[:limit]
+			}
+			return collapsed, nil
+		}
+		fetchLimit *= 2
+	}
+}
@@ -0,0 +1,412 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// TagSeparator splits hierarchical tags such as security/auth
+const TagSeparator = "/"
+
+// NormalizeTag lowercases a tag, turns spaces into dashes and tidies the
+// separators of hierarchical tags
+func NormalizeTag(tag string) string {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(tag)), TagSeparator)
+	clean := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.Join(strings.Fields(p), "-")
+		if p != "" {
+			clean = append(clean, p)
+		}
+	}
+	return strings.Join(clean, TagSeparator)
+}
+
+// CanonicalTags normalizes tags, resolves aliases to their target tag and
+// drops duplicates, keeping the original order
+func CanonicalTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return tags, nil
+	}
+
+	aliases, err := LoadTagAliases()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = resolveAlias(NormalizeTag(t), aliases)
+		if t != "" && !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// resolveAlias maps an alias, or a tag under an aliased parent, to its target
+func resolveAlias(tag string, aliases map[string]string) string {
+	if target, ok := aliases[tag]; ok {
+		return target
+	}
+	// The most specific alias wins when several prefixes match
+	best := ""
+	for alias := range aliases {
+		if strings.HasPrefix(tag, alias+TagSeparator) && len(alias) > len(best) {
+			best = alias
+		}
+	}
+	if best != "" {
+		return aliases[best] + strings.TrimPrefix(tag, best)
+	}
+	return tag
+}
+
+// LoadTagAliases returns alias -> tag
+func LoadTagAliases() (map[string]string, error) {
+	aliases := make(map[string]string)
+
+	output, err := ExecDoltSQLJSON("SELECT alias, tag FROM tag_aliases")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tag aliases: %w", err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, err
+	}
+	for _, row := range result.Rows {
+		aliases[models.AsString(row["alias"])] = models.AsString(row["tag"])
+	}
+
+	return aliases, nil
+}
+
+// tagFilterClause matches memories carrying tag, any tag beneath it in the
+// hierarchy, or any alias of it
+func tagFilterClause(tag string, aliases map[string]string) string {
+	canonical := resolveAlias(NormalizeTag(tag), aliases)
+
+	names := []string{canonical}
+	for alias, target := range aliases {
+		if target == canonical {
+			names = append(names, alias)
+		}
+	}
+	// Also match the raw spelling for rows written before normalization
+	if tag != canonical {
+		names = append(names, tag)
+	}
+
+	var clauses []string
+	for _, name := range names {
+		escaped := strings.ReplaceAll(strings.ReplaceAll(name, `\`, `\\`), "'", "''")
+		clauses = append(clauses,
+			fmt.Sprintf(`JSON_CONTAINS(tags, '"%s"')`, escaped),
+			fmt.Sprintf(`CAST(tags AS CHAR) LIKE '%%"%s/%%'`, strings.NewReplacer("%", `\%`, "_", `\_`).Replace(escaped)),
+		)
+	}
+	return "(" + strings.Join(clauses, " OR ") + ")"
+}
+
+// TagCounts returns every tag in use with the number of memories carrying it
+func TagCounts() ([]TagCount, error) {
+	rows, err := loadMemoryTags()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, tags := range rows {
+		for _, t := range tags {
+			counts[t]++
+		}
+	}
+
+	out := make([]TagCount, 0, len(counts))
+	for t, c := range counts {
+		out = append(out, TagCount{Tag: t, Count: c})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tag < out[j].Tag })
+	return out, nil
+}
+
+// loadMemoryTags returns the tags of every memory that has any, keyed by ID
+func loadMemoryTags() (map[string][]string, error) {
+	output, err := ExecDoltSQLJSON("SELECT id, tags FROM memories WHERE tags IS NOT NULL AND tags != '[]'")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+
+	var result struct {
+		Rows []map[string]interface{} `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		return nil, err
+	}
+
+	rows := make(map[string][]string, len(result.Rows))
+	for _, row := range result.Rows {
+		var tags []string
+		switch val := row["tags"].(type) {
+		case string:
+			json.Unmarshal([]byte(val), &tags)
+		case []interface{}:
+			for _, t := range val {
+				tags = append(tags, fmt.Sprintf("%v", t))
+			}
+		}
+		if len(tags) > 0 {
+			rows[models.AsString(row["id"])] = tags
+		}
+	}
+	return rows, nil
+}
+
+// TagRewrite reports what a bulk tag change touched
+type TagRewrite struct {
+	Memories int `json:"memories"`
+	Aliases  int `json:"aliases"`
+}
+
+// RenameTag renames a tag and everything beneath it (auth/* -> security/auth/*)
+// on every memory, and repoints aliases, in one Dolt commit
+func RenameTag(oldTag, newTag string) (*TagRewrite, error) {
+	return MergeTags([]string{oldTag}, newTag, false)
+}
+
+// MergeTags folds each source tag, with its children, into target in one
+// Dolt commit. With keepAliases the sources become aliases of target so
+// existing queries keep working.
+func MergeTags(sources []string, target string, keepAliases bool) (*TagRewrite, error) {
+	target = NormalizeTag(target)
+	if target == "" {
+		return nil, fmt.Errorf("target tag is empty")
+	}
+
+	from := make([]string, 0, len(sources))
+	for _, s := range sources {
+		s = NormalizeTag(s)
+		if s == "" || s == target {
+			continue
+		}
+		if strings.HasPrefix(target, s+TagSeparator) {
+			return nil, fmt.Errorf("cannot move %s beneath itself (%s)", s, target)
+		}
+		from = append(from, s)
+	}
+	if len(from) == 0 {
+		return nil, fmt.Errorf("no tags to rewrite")
+	}
+
+	rewrite := func(tag string) string {
+		n := NormalizeTag(tag)
+		for _, s := range from {
+			if n == s {
+				return target
+			}
+			if strings.HasPrefix(n, s+TagSeparator) {
+				return target + strings.TrimPrefix(n, s)
+			}
+		}
+		return tag
+	}
+
+	rows, err := loadMemoryTags()
+	if err != nil {
+		return nil, err
+	}
+
+	var script strings.Builder
+	result := &TagRewrite{}
+	for id, tags := range rows {
+		changed := false
+		seen := make(map[string]bool, len(tags))
+		next := make([]string, 0, len(tags))
+		for _, t := range tags {
+			r := rewrite(t)
+			if r != t {
+				changed = true
+			}
+			if seen[r] {
+				changed = true
+				continue
+			}
+			seen[r] = true
+			next = append(next, r)
+		}
+		if !changed {
+			continue
+		}
+		tagsJSON, err := json.Marshal(next)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal tags: %w", err)
+		}
+		fmt.Fprintf(&script, "UPDATE memories SET tags = '%s' WHERE id = '%s';\n",
+			strings.ReplaceAll(string(tagsJSON), "'", "''"), id)
+		result.Memories++
+	}
+
+	aliases, err := LoadTagAliases()
+	if err != nil {
+		return nil, err
+	}
+	// Aliases that pointed at a renamed tag follow it
+	for alias, tag := range aliases {
+		if r := rewrite(tag); r != tag {
+			fmt.Fprintf(&script, "UPDATE tag_aliases SET tag = '%s' WHERE alias = '%s';\n",
+				strings.ReplaceAll(r, "'", "''"), strings.ReplaceAll(alias, "'", "''"))
+			result.Aliases++
+		}
+	}
+	if keepAliases {
+		for _, s := range from {
+			fmt.Fprintf(&script, "REPLACE INTO tag_aliases (alias, tag) VALUES ('%s', '%s');\n",
+				strings.ReplaceAll(s, "'", "''"), strings.ReplaceAll(target, "'", "''"))
+			result.Aliases++
+		}
+	}
+
+	if script.Len() == 0 {
+		return result, nil
+	}
+	if err := db.ExecDoltSQLScript(script.String()); err != nil {
+		return nil, fmt.Errorf("failed to rewrite tags: %w", err)
+	}
+
+	commitMsg := fmt.Sprintf("Merge tags %s into %s (%d memory(ies))", strings.Join(from, ", "), target, result.Memories)
+	if len(from) == 1 && !keepAliases {
+		commitMsg = fmt.Sprintf("Rename tag %s to %s (%d memory(ies))", from[0], target, result.Memories)
+	}
+	if err := DoltCommit(commitMsg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+
+	return result, nil
+}
+
+// AddTagAlias makes alias resolve to tag on add and in recall filters
+func AddTagAlias(alias, tag string) error {
+	alias = NormalizeTag(alias)
+	tag = NormalizeTag(tag)
+	if alias == "" || tag == "" {
+		return fmt.Errorf("alias and tag must not be empty")
+	}
+	if alias == tag {
+		return fmt.Errorf("a tag cannot alias itself")
+	}
+	if strings.HasPrefix(tag, alias+TagSeparator) {
+		return fmt.Errorf("%s cannot alias its own descendant %s", alias, tag)
+	}
+
+	aliases, err := LoadTagAliases()
+	if err != nil {
+		return err
+	}
+	// Point at the final target so lookups never chain
+	tag = resolveAlias(tag, aliases)
+	if tag == alias || strings.HasPrefix(tag, alias+TagSeparator) {
+		return fmt.Errorf("%s already resolves to %s; aliasing it back would loop", tag, alias)
+	}
+
+	script := fmt.Sprintf("REPLACE INTO tag_aliases (alias, tag) VALUES ('%s', '%s');\n",
+		strings.ReplaceAll(alias, "'", "''"), strings.ReplaceAll(tag, "'", "''"))
+	// Existing aliases of the new alias now point straight at tag
+	for a, t := range aliases {
+		if t == alias {
+			script += fmt.Sprintf("UPDATE tag_aliases SET tag = '%s' WHERE alias = '%s';\n",
+				strings.ReplaceAll(tag, "'", "''"), strings.ReplaceAll(a, "'", "''"))
+		}
+	}
+	if err := db.ExecDoltSQLScript(script); err != nil {
+		return fmt.Errorf("failed to add tag alias: %w", err)
+	}
+
+	return DoltCommit(fmt.Sprintf("Alias tag %s to %s", alias, tag))
+}
+
+// RemoveTagAlias deletes an alias
+func RemoveTagAlias(alias string) error {
+	alias = NormalizeTag(alias)
+	query := fmt.Sprintf("DELETE FROM tag_aliases WHERE alias = '%s'", strings.ReplaceAll(alias, "'", "''"))
+	if _, err := db.ExecDoltSQL(query); err != nil {
+		return fmt.Errorf("failed to remove tag alias: %w", err)
+	}
+	return DoltCommit(fmt.Sprintf("Remove tag alias %s", alias))
+}
+
+// TagNode is one level of the tag hierarchy
+type TagNode struct {
+	Name     string     `json:"name"`
+	Path     string     `json:"path"`
+	Count    int        `json:"count"` // Memories tagged exactly with Path
+	Total    int        `json:"total"` // Count plus all descendants
+	Aliases  []string   `json:"aliases,omitempty"`
+	Children []*TagNode `json:"children,omitempty"`
+}
+
+// TagTree builds the tag hierarchy from every tag in use
+func TagTree() ([]*TagNode, error) {
+	counts, err := TagCounts()
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := LoadTagAliases()
+	if err != nil {
+		return nil, err
+	}
+
+	root := &TagNode{}
+	index := map[string]*TagNode{"": root}
+	var node func(path string) *TagNode
+	node = func(path string) *TagNode {
+		if n, ok := index[path]; ok {
+			return n
+		}
+		parentPath, name := "", path
+		if i := strings.LastIndex(path, TagSeparator); i >= 0 {
+			parentPath, name = path[:i], path[i+1:]
+		}
+		n := &TagNode{Name: name, Path: path}
+		parent := node(parentPath)
+		parent.Children = append(parent.Children, n)
+		index[path] = n
+		return n
+	}
+
+	for _, tc := range counts {
+		path := NormalizeTag(tc.Tag)
+		if path == "" {
+			continue
+		}
+		node(path).Count += tc.Count
+	}
+	for alias, tag := range aliases {
+		n := node(tag)
+		n.Aliases = append(n.Aliases, alias)
+	}
+
+	var total func(n *TagNode) int
+	total = func(n *TagNode) int {
+		n.Total = n.Count
+		sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Name < n.Children[j].Name })
+		sort.Strings(n.Aliases)
+		for _, c := range n.Children {
+			n.Total += total(c)
+		}
+		return n.Total
+	}
+	total(root)
+
+	return root.Children, nil
+}
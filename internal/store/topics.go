@@ -0,0 +1,516 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hargabyte/ami/internal/db"
+	"github.com/hargabyte/ami/internal/models"
+)
+
+// maxTopicVocabulary caps the TF-IDF vocabulary so vectors stay dense and small
+const maxTopicVocabulary = 500
+
+// TopicOptions controls topic discovery
+type TopicOptions struct {
+	TeamID          string
+	Statuses        []models.Status
+	Limit           int // Maximum memories to cluster
+	K               int // Number of clusters; 0 picks sqrt(n/2)
+	MinSize         int
+	Representatives int
+	Ollama          *db.OllamaClient // Names topics with the LLM when set
+}
+
+// TagCount is a tag and how many memories in a topic carry it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// Topic is a cluster of related memories
+type Topic struct {
+	Label           string          `json:"label"`
+	Terms           []string        `json:"terms"`
+	Size            int             `json:"size"`
+	AvgDecay        float64         `json:"avg_decay"`
+	DominantTags    []TagCount      `json:"dominant_tags"`
+	Representatives []models.Memory `json:"representatives"`
+	MemoryIDs       []string        `json:"memory_ids"`
+}
+
+// TopicReport is the result of DiscoverTopics
+type TopicReport struct {
+	Method  string  `json:"method"` // "embedding" or "tfidf"
+	Total   int     `json:"total"`
+	Topics  []Topic `json:"topics"`
+	Skipped int     `json:"skipped"` // Memories in clusters below MinSize
+}
+
+// DiscoverTopics clusters memories with spherical k-means, over embeddings
+// when every memory has one and over TF-IDF vectors otherwise, and names
+// each cluster from its most salient terms or with the local LLM
+func DiscoverTopics(ctx context.Context, opts TopicOptions) (*TopicReport, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = 2000
+	}
+	if opts.MinSize <= 0 {
+		opts.MinSize = 2
+	}
+	if opts.Representatives <= 0 {
+		opts.Representatives = 3
+	}
+
+	whereClauses := []string{visibleClause(opts.Statuses)}
+	if opts.TeamID != "" {
+		whereClauses = append(whereClauses, fmt.Sprintf("team_id = '%s'", strings.ReplaceAll(opts.TeamID, "'", "''")))
+	}
+	query := fmt.Sprintf(`
+		SELECT id, content, owner_id, category, priority, created_at, accessed_at, access_count, source, tags, embedding, status, team_id, expires_at
+		FROM memories
+		WHERE %s
+		ORDER BY priority DESC, accessed_at DESC
+		LIMIT %d
+	`, strings.Join(whereClauses, " AND "), opts.Limit)
+
+	output, err := ExecDoltSQLJSON(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memories: %w", err)
+	}
+	memories, err := parseMemoriesJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TopicReport{Total: len(memories), Topics: []Topic{}}
+	if len(memories) == 0 {
+		return report, nil
+	}
+
+	tfidf, vocab := tfidfVectors(memories)
+	vectors, method := embeddingVectors(memories)
+	if vectors == nil {
+		vectors, method = tfidf, "tfidf"
+	}
+	report.Method = method
+
+	k := opts.K
+	if k <= 0 {
+		k = int(math.Round(math.Sqrt(float64(len(memories)) / 2)))
+	}
+	if k < 1 {
+		k = 1
+	}
+	if k > len(memories) {
+		k = len(memories)
+	}
+
+	assignment, centroids := sphericalKMeans(vectors, k, 50)
+
+	now := time.Now()
+	for c := range centroids {
+		var members []int
+		for i, a := range assignment {
+			if a == c {
+				members = append(members, i)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		if len(members) < opts.MinSize {
+			report.Skipped += len(members)
+			continue
+		}
+
+		topic := Topic{Size: len(members)}
+		topic.Terms = salientTerms(tfidf, vocab, members, 5)
+
+		// Representatives are the members closest to the centroid
+		sort.SliceStable(members, func(i, j int) bool {
+			return dot(vectors[members[i]], centroids[c]) > dot(vectors[members[j]], centroids[c])
+		})
+
+		tagCounts := make(map[string]int)
+		decaySum := 0.0
+		for _, i := range members {
+			m := memories[i]
+			topic.MemoryIDs = append(topic.MemoryIDs, m.ID)
+			decaySum += DecayScore(m, now)
+			for _, t := range m.Tags {
+				tagCounts[t]++
+			}
+		}
+		topic.AvgDecay = decaySum / float64(len(members))
+		topic.DominantTags = topTags(tagCounts, 3)
+		for i := 0; i < len(members) && i < opts.Representatives; i++ {
+			rep := memories[members[i]]
+			rep.Embedding = nil
+			topic.Representatives = append(topic.Representatives, rep)
+		}
+
+		topic.Label = strings.Join(topic.Terms[:min(2, len(topic.Terms))], "-")
+		if opts.Ollama != nil {
+			contents := make([]string, 0, len(topic.Representatives))
+			for _, r := range topic.Representatives {
+				contents = append(contents, r.Content)
+			}
+			if name, err := NameTopic(ctx, opts.Ollama, contents, topic.Terms); err == nil {
+				topic.Label = name
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: LLM topic naming failed, using terms: %v\n", err)
+			}
+		}
+		if topic.Label == "" {
+			topic.Label = fmt.Sprintf("topic-%d", c+1)
+		}
+
+		report.Topics = append(report.Topics, topic)
+	}
+
+	sort.SliceStable(report.Topics, func(i, j int) bool {
+		return report.Topics[i].Size > report.Topics[j].Size
+	})
+
+	return report, nil
+}
+
+// NameTopic asks the LLM for a short kebab-case name for a group of memories
+func NameTopic(ctx context.Context, ollama *db.OllamaClient, contents []string, terms []string) (string, error) {
+	var entries strings.Builder
+	for _, c := range contents {
+		entries.WriteString("- ")
+		entries.WriteString(c)
+		entries.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`
+The following notes belong to one topic. Salient terms: %s.
+Name the topic in one to three lowercase words joined by hyphens, e.g. "database-migrations".
+Reply with the name only.
+
+Notes:
+---
+%s---
+Name:`, strings.Join(terms, ", "), entries.String())
+
+	response, err := ollama.Generate(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSpace(response)
+	if line, _, found := strings.Cut(name, "\n"); found {
+		name = line
+	}
+	name = slugify(name)
+	if name == "" {
+		return "", fmt.Errorf("model returned an empty name")
+	}
+	return name, nil
+}
+
+// slugify lowercases s and joins its words with hyphens
+func slugify(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > 4 {
+		words = words[:4]
+	}
+	return strings.Join(words, "-")
+}
+
+// TagTopics adds "<prefix><label>" to every memory in each topic, in one
+// Dolt commit. It returns how many memories gained a tag.
+func TagTopics(topics []Topic, prefix string) (int, error) {
+	var ids []string
+	labelFor := make(map[string]string)
+	for _, t := range topics {
+		// Topic tags follow the same normalization and aliases as any other
+		canonical, err := CanonicalTags([]string{prefix + t.Label})
+		if err != nil {
+			return 0, err
+		}
+		if len(canonical) == 0 {
+			continue
+		}
+		for _, id := range t.MemoryIDs {
+			ids = append(ids, id)
+			labelFor[id] = canonical[0]
+		}
+	}
+
+	memories, err := GetMemoriesByIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	// Every update goes through one dolt sql invocation
+	var script strings.Builder
+	tagged := 0
+	for _, id := range ids {
+		m, ok := memories[id]
+		if !ok {
+			continue
+		}
+		tag := labelFor[id]
+		has := false
+		for _, t := range m.Tags {
+			if t == tag {
+				has = true
+				break
+			}
+		}
+		if has {
+			continue
+		}
+
+		tags := append(append([]string{}, m.Tags...), tag)
+		tagsJSON, err := json.Marshal(tags)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal tags: %w", err)
+		}
+		fmt.Fprintf(&script, "UPDATE memories SET tags = '%s' WHERE id = '%s';\n",
+			strings.ReplaceAll(string(tagsJSON), "'", "''"), id)
+		tagged++
+	}
+
+	if tagged == 0 {
+		return 0, nil
+	}
+	if err := db.ExecDoltSQLScript(script.String()); err != nil {
+		return 0, fmt.Errorf("failed to tag memories: %w", err)
+	}
+	if err := DoltCommit(fmt.Sprintf("Tag %d memory(ies) with %d topic label(s)", tagged, len(topics))); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create Dolt commit: %v\n", err)
+	}
+
+	return tagged, nil
+}
+
+// embeddingVectors returns normalized embeddings when every memory has one of
+// the same size, or nil
+func embeddingVectors(memories []models.Memory) ([][]float64, string) {
+	dim := len(memories[0].Embedding)
+	if dim == 0 {
+		return nil, ""
+	}
+	vectors := make([][]float64, len(memories))
+	for i, m := range memories {
+		if len(m.Embedding) != dim {
+			return nil, ""
+		}
+		v := make([]float64, dim)
+		for j, x := range m.Embedding {
+			v[j] = float64(x)
+		}
+		vectors[i] = normalize(v)
+	}
+	return vectors, "embedding"
+}
+
+// tfidfVectors builds normalized TF-IDF vectors over the most common terms
+// that appear in at least two memories
+func tfidfVectors(memories []models.Memory) ([][]float64, []string) {
+	docs := make([]map[string]int, len(memories))
+	df := make(map[string]int)
+	for i, m := range memories {
+		counts := make(map[string]int)
+		for _, t := range Tokenize(m.Content) {
+			counts[t]++
+		}
+		for _, t := range m.Tags {
+			counts[strings.ToLower(t)]++
+		}
+		docs[i] = counts
+		for t := range counts {
+			df[t]++
+		}
+	}
+
+	var vocab []string
+	for t, n := range df {
+		if n >= 2 || len(memories) < 4 {
+			vocab = append(vocab, t)
+		}
+	}
+	sort.Slice(vocab, func(i, j int) bool {
+		if df[vocab[i]] != df[vocab[j]] {
+			return df[vocab[i]] > df[vocab[j]]
+		}
+		return vocab[i] < vocab[j]
+	})
+	if len(vocab) > maxTopicVocabulary {
+		vocab = vocab[:maxTopicVocabulary]
+	}
+	index := make(map[string]int, len(vocab))
+	for i, t := range vocab {
+		index[t] = i
+	}
+
+	n := float64(len(memories))
+	vectors := make([][]float64, len(memories))
+	for i, counts := range docs {
+		v := make([]float64, len(vocab))
+		for t, c := range counts {
+			if j, ok := index[t]; ok {
+				v[j] = (1 + math.Log(float64(c))) * math.Log(1+n/float64(df[t]))
+			}
+		}
+		vectors[i] = normalize(v)
+	}
+
+	return vectors, vocab
+}
+
+// salientTerms returns the terms whose TF-IDF weight in the cluster most
+// exceeds their weight across all memories
+func salientTerms(vectors [][]float64, vocab []string, members []int, n int) []string {
+	if len(vocab) == 0 {
+		return []string{}
+	}
+
+	inCluster := make(map[int]bool, len(members))
+	clusterMean := make([]float64, len(vocab))
+	for _, i := range members {
+		inCluster[i] = true
+		for j, x := range vectors[i] {
+			clusterMean[j] += x / float64(len(members))
+		}
+	}
+	otherMean := make([]float64, len(vocab))
+	others := len(vectors) - len(members)
+	if others > 0 {
+		for i, v := range vectors {
+			if inCluster[i] {
+				continue
+			}
+			for j, x := range v {
+				otherMean[j] += x / float64(others)
+			}
+		}
+	}
+
+	idx := make([]int, 0, len(vocab))
+	for j := range vocab {
+		if clusterMean[j] > 0 {
+			idx = append(idx, j)
+		}
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		return clusterMean[idx[a]]-otherMean[idx[a]] > clusterMean[idx[b]]-otherMean[idx[b]]
+	})
+
+	terms := make([]string, 0, n)
+	for i := 0; i < len(idx) && i < n; i++ {
+		terms = append(terms, vocab[idx[i]])
+	}
+	return terms
+}
+
+// sphericalKMeans clusters unit vectors by cosine similarity. Centroids are
+// seeded deterministically with farthest-point selection.
+func sphericalKMeans(vectors [][]float64, k, iterations int) ([]int, [][]float64) {
+	centroids := make([][]float64, 0, k)
+	centroids = append(centroids, append([]float64{}, vectors[0]...))
+	best := make([]float64, len(vectors))
+	for i := range best {
+		best[i] = dot(vectors[i], centroids[0])
+	}
+	for len(centroids) < k {
+		far := 0
+		for i := range vectors {
+			if best[i] < best[far] {
+				far = i
+			}
+		}
+		centroids = append(centroids, append([]float64{}, vectors[far]...))
+		for i := range vectors {
+			best[i] = math.Max(best[i], dot(vectors[i], centroids[len(centroids)-1]))
+		}
+	}
+
+	assignment := make([]int, len(vectors))
+	for iter := 0; iter < iterations; iter++ {
+		changed := false
+		for i, v := range vectors {
+			bestC, bestSim := 0, math.Inf(-1)
+			for c, centroid := range centroids {
+				if sim := dot(v, centroid); sim > bestSim {
+					bestC, bestSim = c, sim
+				}
+			}
+			if assignment[i] != bestC {
+				assignment[i] = bestC
+				changed = true
+			}
+		}
+		if !changed && iter > 0 {
+			break
+		}
+
+		for c := range centroids {
+			sum := make([]float64, len(centroids[c]))
+			count := 0
+			for i, a := range assignment {
+				if a != c {
+					continue
+				}
+				count++
+				for j, x := range vectors[i] {
+					sum[j] += x
+				}
+			}
+			if count > 0 {
+				centroids[c] = normalize(sum)
+			}
+		}
+	}
+
+	return assignment, centroids
+}
+
+// topTags returns the n most common tags
+func topTags(counts map[string]int, n int) []TagCount {
+	tags := make([]TagCount, 0, len(counts))
+	for t, c := range counts {
+		tags = append(tags, TagCount{Tag: t, Count: c})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	if len(tags) > n {
+		tags = tags[:n]
+	}
+	return tags
+}
+
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func normalize(v []float64) []float64 {
+	norm := math.Sqrt(dot(v, v))
+	if norm == 0 {
+		return v
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
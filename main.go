@@ -3,10 +3,15 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/hargabyte/ami/internal/db"
@@ -25,6 +30,52 @@ func confirmAction(prompt string) bool {
 	return strings.ToLower(response) == "y" || strings.ToLower(response) == "yes"
 }
 
+// parseStatusFilter turns --status and --include-unreviewed into the statuses
+// a read should return. An empty result means the store default (verified).
+func parseStatusFilter(statuses []string, includeUnreviewed bool) ([]models.Status, error) {
+	var result []models.Status
+	for _, raw := range statuses {
+		raw = strings.TrimSpace(raw)
+		if raw == "all" {
+			return []models.Status{models.StatusVerified, models.StatusUnderReview, models.StatusDeprecated, models.StatusArchived}, nil
+		}
+		st := models.Status(raw)
+		if !st.IsValid() {
+			return nil, fmt.Errorf("invalid status '%s'. Must be one of: verified, under_review, deprecated, archived, all", raw)
+		}
+		result = append(result, st)
+	}
+
+	if includeUnreviewed {
+		if len(result) == 0 {
+			result = append(result, models.StatusVerified)
+		}
+		result = append(result, models.StatusUnderReview)
+	}
+
+	return result, nil
+}
+
+// extendExpiry pushes the TTL of expiring memories that were just read.
+// Failures only warn so a read never fails because of it.
+func extendExpiry(memories []models.Memory, raw string) {
+	ttl, err := store.ParseTTL(raw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+
+	var ids []string
+	for _, m := range memories {
+		if m.ExpiresAt != nil {
+			ids = append(ids, m.ID)
+		}
+	}
+	if _, err := store.ExtendTTL(ids, ttl); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "chaos",
@@ -44,10 +95,14 @@ Features:
 	rootCmd.AddCommand(updateCmd())
 	rootCmd.AddCommand(recallCmd())
 	rootCmd.AddCommand(catchupCmd())
+	rootCmd.AddCommand(showCmd())
 	rootCmd.AddCommand(historyCmd())
 	rootCmd.AddCommand(rollbackCmd())
 	rootCmd.AddCommand(linkCmd())
+	rootCmd.AddCommand(supersedeCmd())
+	rootCmd.AddCommand(graphCmd())
 	rootCmd.AddCommand(keystonesCmd())
+	rootCmd.AddCommand(topicsCmd())
 	rootCmd.AddCommand(statsCmd())
 	rootCmd.AddCommand(contextCmd())
 	rootCmd.AddCommand(syncCmd())
@@ -55,11 +110,14 @@ Features:
 	rootCmd.AddCommand(helpAgentsCmd())
 	rootCmd.AddCommand(deleteCmd())
 	rootCmd.AddCommand(tagsCmd())
+	rootCmd.AddCommand(enrichCmd())
 	rootCmd.AddCommand(checkpointCmd())
 	rootCmd.AddCommand(consolidateCmd())
 	rootCmd.AddCommand(decisionCmd())
 	rootCmd.AddCommand(reflectCmd())
 	rootCmd.AddCommand(conflictCmd())
+	rootCmd.AddCommand(reviewCmd())
+	rootCmd.AddCommand(expireCmd())
 	rootCmd.AddCommand(pairingCmd())
 	rootCmd.AddCommand(robotCmd())
 
@@ -76,6 +134,10 @@ func addCmd() *cobra.Command {
 	var tags []string
 	var source string
 	var teamID string
+	var onDuplicate string
+	var ttl string
+	var supersedes string
+	var enrich bool
 	var robotMode bool
 
 	cmd := &cobra.Command{
@@ -133,8 +195,63 @@ func addCmd() *cobra.Command {
 				}
 			}
 
+			// Validate duplicate policy
+			policy := store.DuplicatePolicy(onDuplicate)
+			if !policy.IsValid() {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"invalid --on-duplicate %s"}`+"\n", onDuplicate)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: invalid --on-duplicate '%s'. Must be one of: reject, merge, link, allow\n", onDuplicate)
+				}
+				os.Exit(1)
+			}
+
+			// Parse TTL; unset leaves the category default in place
+			var ttlDuration *time.Duration
+			if cmd.Flags().Changed("ttl") {
+				d, err := store.ParseTTL(ttl)
+				if err != nil {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					}
+					os.Exit(1)
+				}
+				ttlDuration = &d
+			}
+
+			params := store.AddParams{
+				Content:     content,
+				OwnerID:     ownerID,
+				Category:    cat,
+				Priority:    priority,
+				Tags:        tags,
+				Source:      source,
+				TeamID:      teamID,
+				TTL:         ttlDuration,
+				Supersedes:  supersedes,
+				OnDuplicate: policy,
+			}
+
+			// Enrichment is best effort: the memory is still added if Ollama is down
+			var enrichment *store.Enrichment
+			if enrich {
+				enrichment, err = store.EnrichAddParams(context.Background(), db.NewOllamaClientFromEnv(), &params)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: enrichment failed, adding without it: %v\n", err)
+				}
+				// Values the user passed explicitly always win
+				if cmd.Flags().Changed("category") {
+					params.Category = cat
+				}
+				if cmd.Flags().Changed("priority") {
+					params.Priority = priority
+				}
+			}
+
 			// Add the memory
-			memory, err := store.AddMemory(content, ownerID, cat, priority, tags, source, teamID)
+			added, err := store.AddMemoryWithParams(params)
 			if err != nil {
 				if robotMode {
 					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
@@ -143,17 +260,52 @@ func addCmd() *cobra.Command {
 				}
 				os.Exit(1)
 			}
+			memory := added.Memory
 
 			if robotMode {
+				status := "ok"
+				if added.Action == "rejected" {
+					status = "duplicate"
+				}
 				result := map[string]interface{}{
-					"status": "ok",
-					"memory": memory,
+					"status":    status,
+					"action":    added.Action,
+					"memory":    memory,
+					"duplicate": added.Duplicate,
+				}
+				if enrichment != nil {
+					result["enrichment"] = enrichment
 				}
 				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
 				fmt.Println(string(jsonBytes))
-			} else {
+				if added.Action == "rejected" {
+					os.Exit(1)
+				}
+				return
+			}
+
+			switch added.Action {
+			case "rejected":
+				fmt.Fprintf(os.Stderr, "Error: duplicate of memory %s (%s match, similarity %.2f)\n", memory.ID, added.Duplicate.Method, added.Duplicate.Similarity)
+				fmt.Fprintf(os.Stderr, "   Existing: %s\n", memory.Content)
+				os.Exit(1)
+			case "merged":
+				fmt.Printf("✓ Merged into existing memory %s (%s match, priority now %.2f)\n", memory.ID, added.Duplicate.Method, memory.Priority)
+			case "linked":
+				fmt.Printf("✓ Added memory %s (category: %s, priority: %.1f)\n", memory.ID, memory.Category, memory.Priority)
+				fmt.Printf("  Linked as duplicate_of %s (%s match)\n", added.Duplicate.Memory.ID, added.Duplicate.Method)
+			default:
 				fmt.Printf("✓ Added memory %s (category: %s, priority: %.1f)\n", memory.ID, memory.Category, memory.Priority)
 			}
+			if enrichment != nil && len(memory.Tags) > 0 {
+				fmt.Printf("  Tags: %s\n", strings.Join(memory.Tags, ", "))
+			}
+			if memory.ExpiresAt != nil {
+				fmt.Printf("  Expires at %s\n", memory.ExpiresAt.Format("2006-01-02 15:04"))
+			}
+			if supersedes != "" {
+				fmt.Printf("  Supersedes %s (now deprecated)\n", supersedes)
+			}
 		},
 	}
 	cmd.Flags().StringVar(&category, "category", "episodic", "Memory category (core|semantic|working|episodic)")
@@ -162,6 +314,10 @@ func addCmd() *cobra.Command {
 	cmd.Flags().StringSliceVar(&tags, "tags", []string{}, "Tags for the memory")
 	cmd.Flags().StringVar(&source, "source", "", "Source of the memory (optional)")
 	cmd.Flags().StringVar(&teamID, "team", "system", "Mattermost Team ID (optional)")
+	cmd.Flags().StringVar(&onDuplicate, "on-duplicate", "merge", "Action when a near-duplicate exists (reject|merge|link|allow)")
+	cmd.Flags().StringVar(&supersedes, "supersedes", "", "ID of an older memory this one replaces (the old one is deprecated; merge becomes link)")
+	cmd.Flags().StringVar(&ttl, "ttl", "", "Expire after this long, e.g. 48h or 7d; \"never\" disables (working memories default to AMI_WORKING_TTL or 72h)")
+	cmd.Flags().BoolVar(&enrich, "enrich", false, "Suggest tags, category and priority with the local Ollama model")
 	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
 	return cmd
 }
@@ -172,6 +328,7 @@ func updateCmd() *cobra.Command {
 	var priority float64
 	var tags []string
 	var source string
+	var ttl string
 	var robotMode bool
 
 	cmd := &cobra.Command{
@@ -245,6 +402,19 @@ func updateCmd() *cobra.Command {
 				params.Source = &source
 			}
 
+			if cmd.Flags().Changed("ttl") {
+				d, err := store.ParseTTL(ttl)
+				if err != nil {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					}
+					os.Exit(1)
+				}
+				params.TTL = &d
+			}
+
 			// Content is provided as args
 			if len(args) > 1 {
 				content := args[1]
@@ -278,12 +448,15 @@ func updateCmd() *cobra.Command {
 	cmd.Flags().Float64Var(&priority, "priority", -1, "Priority (0.0-1.0)")
 	cmd.Flags().StringSliceVar(&tags, "tags", []string{}, "Tags for the memory")
 	cmd.Flags().StringVar(&source, "source", "", "Source of the memory (optional)")
+	cmd.Flags().StringVar(&ttl, "ttl", "", "Reset expiry to now + TTL (e.g. 48h, 7d), or \"never\" to make permanent")
 	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
 	return cmd
 }
 
 func recallCmd() *cobra.Command {
 	var robotMode bool
+	var statusFilter []string
+	var includeUnreviewed bool
 	var limit int
 	var tagsFilter []string
 	var categoryFilter string
@@ -291,6 +464,7 @@ func recallCmd() *cobra.Command {
 	var teamFilter string
 	var withDecay bool
 	var semanticSearch bool
+	var extendTTL string
 
 	cmd := &cobra.Command{
 		Use:   "recall [query]",
@@ -318,6 +492,16 @@ func recallCmd() *cobra.Command {
 			}
 			defer db.CloseDB()
 
+			statuses, err := parseStatusFilter(statusFilter, includeUnreviewed)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
 			query := ""
 			if len(args) > 0 {
 				query = args[0]
@@ -333,6 +517,7 @@ func recallCmd() *cobra.Command {
 				TeamID:     teamFilter,
 				WithDecay:  withDecay,
 				Semantic:   semanticSearch,
+				Statuses:   statuses,
 			}
 
 			// Search memories
@@ -346,6 +531,10 @@ func recallCmd() *cobra.Command {
 				os.Exit(1)
 			}
 
+			if extendTTL != "" {
+				extendExpiry(memories, extendTTL)
+			}
+
 			if robotMode {
 				// Robot Mode: Pure JSON to stdout
 				result := map[string]interface{}{
@@ -415,11 +604,16 @@ func recallCmd() *cobra.Command {
 	cmd.Flags().StringVar(&teamFilter, "team", "", "Filter by Mattermost Team ID")
 	cmd.Flags().BoolVar(&withDecay, "decay", false, "Use decay-weighted scoring for recall")
 	cmd.Flags().BoolVar(&semanticSearch, "semantic", false, "Use embeddings-based semantic search")
+	cmd.Flags().StringVar(&extendTTL, "extend-ttl", "", "Push expiring results out to now + TTL (e.g. 24h)")
+	cmd.Flags().StringSliceVar(&statusFilter, "status", []string{}, "Statuses to include (verified|under_review|deprecated|archived|all, default verified)")
+	cmd.Flags().BoolVar(&includeUnreviewed, "include-unreviewed", false, "Also include memories awaiting review")
 	return cmd
 }
 
 func catchupCmd() *cobra.Command {
 	var robotMode bool
+	var statusFilter []string
+	var includeUnreviewed bool
 	var limit int
 	var category string
 	var since string
@@ -449,10 +643,21 @@ func catchupCmd() *cobra.Command {
 			}
 			defer db.CloseDB()
 
+			statuses, err := parseStatusFilter(statusFilter, includeUnreviewed)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
 			opts := store.CatchupOptions{
 				Limit:    limit,
 				Category: category,
 				Since:    since,
+				Statuses: statuses,
 			}
 
 			memories, err := store.CatchupMemories(opts)
@@ -486,6 +691,139 @@ func catchupCmd() *cobra.Command {
 	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of results")
 	cmd.Flags().StringVar(&category, "category", "", "Filter by category")
 	cmd.Flags().StringVar(&since, "since", "", "Filter by creation time (YYYY-MM-DD HH:MM:SS)")
+	cmd.Flags().StringSliceVar(&statusFilter, "status", []string{}, "Statuses to include (verified|under_review|deprecated|archived|all, default verified)")
+	cmd.Flags().BoolVar(&includeUnreviewed, "include-unreviewed", false, "Also include memories awaiting review")
+	return cmd
+}
+
+func showCmd() *cobra.Command {
+	var robotMode bool
+
+	cmd := &cobra.Command{
+		Use:   "show [id]",
+		Short: "Show a memory, its supersession lineage and its track record",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, err := os.Getwd()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if err := db.InitDB(repoPath); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			defer db.CloseDB()
+
+			id := args[0]
+			memory, err := store.GetMemoryByID(id)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			lineage, err := store.GetLineage(id)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting lineage: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			links, err := store.GetMemoryLinks(id)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting links: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			record, err := store.GetTrackRecord(id)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting track record: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":       "ok",
+					"memory":       memory,
+					"lineage":      lineage,
+					"links":        links,
+					"track_record": record,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			fmt.Printf("Memory %s\n", memory.ID)
+			fmt.Printf("  Content:  %s\n", memory.Content)
+			fmt.Printf("  Category: %s | Priority: %.2f | Status: %s\n", memory.Category, memory.Priority, memory.Status)
+			fmt.Printf("  Owner:    %s | Team: %s\n", memory.OwnerID, memory.TeamID)
+			if len(memory.Tags) > 0 {
+				fmt.Printf("  Tags:     %v\n", memory.Tags)
+			}
+			if memory.ExpiresAt != nil {
+				fmt.Printf("  Expires:  %s\n", memory.ExpiresAt.Format("2006-01-02 15:04"))
+			}
+
+			if len(lineage) > 1 {
+				fmt.Printf("\nLineage (oldest first):\n")
+				for i, m := range lineage {
+					marker := " "
+					if m.ID == memory.ID {
+						marker = "*"
+					}
+					current := ""
+					if i == len(lineage)-1 {
+						current = " [current]"
+					}
+					fmt.Printf("  %s %d. %s (%s, %s)%s\n", marker, i+1, m.ID, m.Status, m.CreatedAt.Format("2006-01-02"), current)
+					fmt.Printf("       %s\n", m.Content)
+				}
+			}
+
+			if len(links) > 0 {
+				fmt.Printf("\nLinks:\n")
+				for _, l := range links {
+					fmt.Printf("  - %s -> %s (%s)\n", l["from_id"], l["to_id"], l["relation"])
+				}
+			}
+
+			if record.Decisions > 0 {
+				fmt.Printf("\nTrack record: confidence %.2f from %d decision(s) (%.2f successes, %.2f failures)\n",
+					record.Confidence, record.Decisions, record.Successes, record.Failures)
+				for _, c := range record.Credits {
+					fmt.Printf("  - %s outcome %.2f, share %.2f, priority %+.3f (%s)\n",
+						c.DecisionID, c.Outcome, c.Weight, c.PriorityDelta, c.RecordedAt.Format("2006-01-02"))
+				}
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
 	return cmd
 }
 
@@ -610,7 +948,14 @@ func linkCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "link [from-id] [to-id] [relation]",
 		Short: "Link two memories together",
-		Args:  cobra.MinimumNArgs(2),
+		Long: `Link two memories with a typed relation (default "related").
+
+The relation must be registered; run "ami link types" for the vocabulary.
+Inverse names are accepted and stored in canonical form, so
+"ami link A B required_by" records B depends_on A. Symmetric relations are
+stored once, and links that would close a cycle in an acyclic relation
+(depends_on, derived_from, supersedes, ...) are rejected.`,
+		Args: cobra.MinimumNArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Initialize database
 			repoPath, err := os.Getwd()
@@ -679,8 +1024,44 @@ func linkCmd() *cobra.Command {
 			} else {
 				fmt.Printf("Links for %s:\n", args[0])
 				for _, l := range links {
-					fmt.Printf("- %s -> %s (%s)\n", l["from_id"], l["to_id"], l["relation"])
+					if l["from_id"] == args[0] {
+						fmt.Printf("- %s %s\n", l["relation"], l["to_id"])
+					} else {
+						fmt.Printf("- %s %s\n", store.RelationLabel(l["relation"], false), l["from_id"])
+					}
+				}
+			}
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "types",
+		Short: "List registered relation types",
+		Run: func(cmd *cobra.Command, args []string) {
+			relations := store.Relations()
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":    "ok",
+					"relations": relations,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			fmt.Printf("Relation types (%d):\n\n", len(relations))
+			for _, r := range relations {
+				rules := []string{}
+				if r.Symmetric {
+					rules = append(rules, "symmetric")
+				} else {
+					rules = append(rules, "inverse: "+r.Inverse)
 				}
+				if r.Acyclic {
+					rules = append(rules, "acyclic")
+				}
+				fmt.Printf("  %-14s %s (%s)\n", r.Name, r.Description, strings.Join(rules, ", "))
 			}
 		},
 	})
@@ -689,13 +1070,22 @@ func linkCmd() *cobra.Command {
 	return cmd
 }
 
-func keystonesCmd() *cobra.Command {
+func supersedeCmd() *cobra.Command {
 	var robotMode bool
-	var limit int
 
 	cmd := &cobra.Command{
-		Use:   "keystones",
-		Short: "Identify core/foundational memories",
+		Use:   "supersede [old-id] [new-id]",
+		Short: "Mark a memory as replaced by a newer one",
+		Long: `Record that new-id is the current version of the fact in old-id.
+
+A "supersedes" link is created from the new memory to the old one and the old
+memory is deprecated. Recall and keystones collapse supersession chains to
+their newest member, and "ami show" prints the whole lineage.
+
+Example:
+  ami add "We migrated to CockroachDB" --category semantic
+  ami supersede <postgres-memory-id> <cockroach-memory-id>`,
+		Args: cobra.ExactArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Initialize database
 			repoPath, err := os.Getwd()
@@ -718,91 +1108,2539 @@ func keystonesCmd() *cobra.Command {
 			}
 			defer db.CloseDB()
 
-			keystones, err := store.GetKeystoneMemories(limit)
-			if err != nil {
+			oldID, newID := args[0], args[1]
+			if err := store.SupersedeMemory(oldID, newID); err != nil {
 				if robotMode {
 					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
 				} else {
-					fmt.Fprintf(os.Stderr, "Error getting keystones: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Error superseding memory: %v\n", err)
 				}
 				os.Exit(1)
 			}
 
 			if robotMode {
-				result := map[string]interface{}{
-					"status":    "ok",
-					"count":     len(keystones),
-					"keystones": keystones,
-				}
-				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
-				fmt.Println(string(jsonBytes))
+				fmt.Printf(`{"status":"ok","message":"%s supersedes %s"}`+"\n", newID, oldID)
 			} else {
-				fmt.Printf("Keystone Memories (%d):\n\n", len(keystones))
-				for i, m := range keystones {
-					fmt.Printf("%d. [%s] %s (Priority: %.1f, Accesses: %d)\n", i+1, m.Category, m.ID, m.Priority, m.AccessCount)
-					fmt.Printf("   %s\n\n", m.Content)
-				}
+				fmt.Printf("✓ %s now supersedes %s (deprecated)\n", newID, oldID)
 			}
 		},
 	}
 	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
-	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of results")
 	return cmd
 }
 
-func statsCmd() *cobra.Command {
+// graphFilters holds the recall-style filters shared by graph subcommands
+type graphFilters struct {
+	query             string
+	tags              []string
+	category          string
+	owner             string
+	team              string
+	statuses          []string
+	includeUnreviewed bool
+	limit             int
+}
+
+func (f *graphFilters) bind(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.query, "query", "", "Only memories whose content contains this text")
+	cmd.Flags().StringSliceVar(&f.tags, "tag", []string{}, "Filter by tags (all tags must match)")
+	cmd.Flags().StringVar(&f.category, "category", "", "Filter by category (core|semantic|working|episodic)")
+	cmd.Flags().StringVar(&f.owner, "owner", "", "Filter by memory owner")
+	cmd.Flags().StringVar(&f.team, "team", "", "Filter by Mattermost Team ID")
+	cmd.Flags().StringSliceVar(&f.statuses, "status", []string{}, "Statuses to include (verified|under_review|deprecated|archived|all, default verified)")
+	cmd.Flags().BoolVar(&f.includeUnreviewed, "include-unreviewed", false, "Also include memories awaiting review")
+	cmd.Flags().IntVar(&f.limit, "limit", 0, "Maximum number of memories (0 for no limit)")
+}
+
+func (f *graphFilters) options() (store.RecallOptions, error) {
+	statuses, err := parseStatusFilter(f.statuses, f.includeUnreviewed)
+	if err != nil {
+		return store.RecallOptions{}, err
+	}
+	return store.RecallOptions{
+		Query:    f.query,
+		Limit:    f.limit,
+		Tags:     f.tags,
+		Category: f.category,
+		OwnerID:  f.owner,
+		TeamID:   f.team,
+		Statuses: statuses,
+	}, nil
+}
+
+func graphCmd() *cobra.Command {
 	var robotMode bool
+	var depth int
+	var relations []string
+	var maxDepth int
+	var filters graphFilters
+	var exportFilters graphFilters
+	var htmlFilters graphFilters
+	var format string
+	var output string
+	var htmlOutput string
 
 	cmd := &cobra.Command{
-		Use:   "stats",
-		Short: "Show memory database analytics",
+		Use:   "graph",
+		Short: "Query the memory link graph",
+		Long: `Walk the graph formed by memory_links.
+
+Subcommands:
+  neighbors <id>     - Memories linked to <id>, optionally several hops out
+  path <a> <b>       - Shortest chain of links between two memories
+  subgraph           - Memories matching recall filters and the links among them
+  export             - Write a subgraph as dot, graphml, mermaid or cytoscape-json
+  html               - Write a self-contained interactive HTML explorer
+
+Links are followed in both directions; an incoming edge is shown under its
+inverse name (e.g. required_by for depends_on). See "ami link types".
+
+Examples:
+  ami graph neighbors abc-123 --depth 2 --relation depends_on
+  ami graph path abc-123 def-456
+  ami graph subgraph --tag security
+  ami graph export --format dot --team AMI-Dev -o brain.dot
+  ami graph html -o brain.html`,
+	}
+
+	neighborsCmd := &cobra.Command{
+		Use:   "neighbors <id>",
+		Short: "List memories linked to a memory",
+		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			// Initialize database
 			repoPath, _ := os.Getwd()
 			db.InitDB(repoPath)
 			defer db.CloseDB()
 
-			stats, err := store.GetMemoryStats()
+			neighbors, err := store.GetNeighbors(args[0], depth, relations)
 			if err != nil {
 				if robotMode {
 					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
 				} else {
-					fmt.Fprintf(os.Stderr, "Error getting stats: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Error finding neighbors: %v\n", err)
 				}
 				os.Exit(1)
 			}
 
 			if robotMode {
-				jsonBytes, _ := json.MarshalIndent(stats, "", "  ")
-				fmt.Println(string(jsonBytes))
-			} else {
-				fmt.Println("AMI Memory Statistics")
-				fmt.Printf("Total Memories: %v\n", stats["total_memories"])
-				fmt.Println("\nDistribution by Category:")
-				dist := stats["distribution"].(map[string]int)
-				for cat, count := range dist {
-					fmt.Printf("- %-10s: %d\n", cat, count)
+				result := map[string]interface{}{
+					"status":    "ok",
+					"id":        args[0],
+					"count":     len(neighbors),
+					"neighbors": neighbors,
 				}
-				fmt.Println("\nMetrics:")
-				metrics := stats["metrics"].(map[string]interface{})
-				fmt.Printf("- Avg Priority:  %.2f\n", metrics["avg_priority"])
-				fmt.Printf("- Avg Access:    %.2f\n", metrics["avg_access_count"])
-				fmt.Printf("- Avg Decay Score: %.2f\n", metrics["avg_decay_score"])
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			if len(neighbors) == 0 {
+				fmt.Printf("No linked memories for %s.\n", args[0])
+				return
+			}
+			fmt.Printf("Neighbors of %s (%d):\n\n", args[0], len(neighbors))
+			for _, n := range neighbors {
+				indent := strings.Repeat("  ", n.Depth)
+				fmt.Printf("%s%s %s [%s]\n", indent, n.Via.Label, n.Memory.ID, n.Memory.Category)
+				fmt.Printf("%s  %s\n", indent, n.Memory.Content)
 			}
 		},
 	}
-	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
-	return cmd
-}
-
-func contextCmd() *cobra.Command {
-	var robotMode bool
-	var limit int
-	var tokenBudget int
+	neighborsCmd.Flags().IntVar(&depth, "depth", 1, "Number of hops to follow")
+	neighborsCmd.Flags().StringSliceVar(&relations, "relation", []string{}, "Only follow these relations (canonical or inverse names)")
+
+	pathCmd := &cobra.Command{
+		Use:   "path <from-id> <to-id>",
+		Short: "Find the shortest link path between two memories",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			path, err := store.FindPath(args[0], args[1], maxDepth)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error finding path: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status": "ok",
+					"from":   args[0],
+					"to":     args[1],
+					"found":  path != nil,
+					"hops":   len(path),
+					"path":   path,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			if path == nil {
+				fmt.Printf("No path between %s and %s.\n", args[0], args[1])
+				return
+			}
+			fmt.Printf("Path from %s to %s (%d hop(s)):\n\n", args[0], args[1], len(path))
+			at := args[0]
+			fmt.Printf("  %s\n", at)
+			for _, step := range path {
+				next := step.ToID
+				label := step.Relation
+				if step.ToID == at {
+					next = step.FromID
+					label = step.Label
+				}
+				fmt.Printf("    --%s--> %s\n", label, next)
+				at = next
+			}
+		},
+	}
+	pathCmd.Flags().IntVar(&maxDepth, "max-depth", 6, "Give up after this many hops (0 for unlimited)")
+
+	subgraphCmd := &cobra.Command{
+		Use:   "subgraph",
+		Short: "Show memories matching filters and the links among them",
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			opts, err := filters.options()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			graph, err := store.GetSubgraph(opts)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error building subgraph: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status": "ok",
+					"nodes":  graph.Nodes,
+					"edges":  graph.Edges,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			fmt.Printf("Subgraph: %d memory(ies), %d link(s)\n\n", len(graph.Nodes), len(graph.Edges))
+			for _, m := range graph.Nodes {
+				fmt.Printf("- %s [%s] %s\n", m.ID, m.Category, m.Content)
+			}
+			if len(graph.Edges) > 0 {
+				fmt.Println()
+				for _, e := range graph.Edges {
+					fmt.Printf("  %s --%s--> %s\n", e.FromID, e.Relation, e.ToID)
+				}
+			}
+		},
+	}
+	filters.bind(subgraphCmd)
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the memory graph for external tools",
+		Long: `Export memories as nodes and memory_links as edges.
+
+Nodes carry category, priority, decay score and team. The recall filters
+(--tag, --team, --category, --owner, --query, --status) select which memories
+are exported; only links between exported memories are included.
+
+Formats: dot, graphml, mermaid, cytoscape-json`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Checked up front so a bad --format never truncates --output
+			if err := store.ValidateExportFormat(format); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			opts, err := exportFilters.options()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			graph, err := store.GetSubgraph(opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error building graph: %v\n", err)
+				os.Exit(1)
+			}
+
+			w := os.Stdout
+			if output != "" && output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", output, err)
+					os.Exit(1)
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if err := store.ExportGraph(graph, format, w); err != nil {
+				fmt.Fprintf(os.Stderr, "Error exporting graph: %v\n", err)
+				os.Exit(1)
+			}
+
+			if w != os.Stdout {
+				fmt.Fprintf(os.Stderr, "✓ Exported %d memory(ies) and %d link(s) to %s\n", len(graph.Nodes), len(graph.Edges), output)
+			}
+		},
+	}
+	exportCmd.Flags().StringVar(&format, "format", "dot", "Output format (dot|graphml|mermaid|cytoscape-json)")
+	exportCmd.Flags().StringVarP(&output, "output", "o", "", "Write to this file instead of stdout")
+	exportFilters.bind(exportCmd)
+
+	htmlCmd := &cobra.Command{
+		Use:   "html",
+		Short: "Write an interactive HTML knowledge-graph explorer",
+		Long: `Write a single HTML file with embedded JavaScript that needs no network
+access. It has a force-directed layout, search, category and team filters,
+nodes coloured by decay score, and a side panel showing each memory's
+content, history and the decisions that used it.
+
+Takes the same filters as "ami graph export".`,
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			opts, err := htmlFilters.options()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			graph, err := store.GetSubgraph(opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error building graph: %v\n", err)
+				os.Exit(1)
+			}
+
+			f, err := os.Create(htmlOutput)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating %s: %v\n", htmlOutput, err)
+				os.Exit(1)
+			}
+			defer f.Close()
+
+			if err := store.WriteGraphHTML(graph, f); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing explorer: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("✓ Wrote %s (%d memory(ies), %d link(s))\n", htmlOutput, len(graph.Nodes), len(graph.Edges))
+		},
+	}
+	htmlCmd.Flags().StringVarP(&htmlOutput, "output", "o", "brain.html", "HTML file to write")
+	htmlFilters.bind(htmlCmd)
+
+	cmd.AddCommand(neighborsCmd)
+	cmd.AddCommand(pathCmd)
+	cmd.AddCommand(subgraphCmd)
+	cmd.AddCommand(exportCmd)
+	cmd.AddCommand(htmlCmd)
+	cmd.PersistentFlags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	return cmd
+}
+
+func keystonesCmd() *cobra.Command {
+	var robotMode bool
+	var statusFilter []string
+	var includeUnreviewed bool
+	var limit int
+	var mode string
+	var weight float64
+	var explain bool
+
+	cmd := &cobra.Command{
+		Use:   "keystones",
+		Short: "Identify core/foundational memories",
+		Long: `Rank memories by how foundational they are.
+
+Modes:
+  popularity   - (priority * 2) + (access_count / 10) (default)
+  pagerank     - Popularity blended with PageRank over memory_links, plus
+                 edges between memories cited by the same decision
+  betweenness  - Popularity blended with betweenness over the same graph
+
+--weight sets the share of the score taken by centrality. Centrality is
+cached per Dolt commit, so repeated calls are cheap until the brain changes.
+--explain prints how each score was built.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, err := os.Getwd()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if err := db.InitDB(repoPath); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			defer db.CloseDB()
+
+			statuses, err := parseStatusFilter(statusFilter, includeUnreviewed)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			keystoneMode := store.KeystoneMode(mode)
+			if keystoneMode != store.KeystonePopularity || explain {
+				scores, err := store.GetCentralKeystones(store.KeystoneOptions{
+					Limit:    limit,
+					Statuses: statuses,
+					Mode:     keystoneMode,
+					Weight:   &weight,
+				})
+				if err != nil {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Error getting keystones: %v\n", err)
+					}
+					os.Exit(1)
+				}
+
+				if robotMode {
+					result := map[string]interface{}{
+						"status":    "ok",
+						"mode":      keystoneMode,
+						"count":     len(scores),
+						"keystones": scores,
+					}
+					jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+					fmt.Println(string(jsonBytes))
+					return
+				}
+
+				fmt.Printf("Keystone Memories (%d, %s):\n\n", len(scores), keystoneMode)
+				for i, ks := range scores {
+					m := ks.Memory
+					fmt.Printf("%d. [%s] %s (Score: %.3f, Priority: %.1f, Accesses: %d)\n", i+1, m.Category, m.ID, ks.Score, m.Priority, m.AccessCount)
+					fmt.Printf("   %s\n", m.Content)
+					if explain {
+						fmt.Printf("   Why: %s\n", ks.Explanation)
+					}
+					fmt.Println()
+				}
+				return
+			}
+
+			keystones, err := store.GetKeystoneMemories(limit, statuses)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting keystones: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":    "ok",
+					"count":     len(keystones),
+					"keystones": keystones,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+			} else {
+				fmt.Printf("Keystone Memories (%d):\n\n", len(keystones))
+				for i, m := range keystones {
+					fmt.Printf("%d. [%s] %s (Priority: %.1f, Accesses: %d)\n", i+1, m.Category, m.ID, m.Priority, m.AccessCount)
+					fmt.Printf("   %s\n\n", m.Content)
+				}
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of results")
+	cmd.Flags().StringVar(&mode, "mode", "popularity", "Ranking mode (popularity|pagerank|betweenness)")
+	cmd.Flags().Float64Var(&weight, "weight", 0.5, "Share of the score taken by centrality (0-1)")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Explain how each keystone's score was computed")
+	cmd.Flags().StringSliceVar(&statusFilter, "status", []string{}, "Statuses to include (verified|under_review|deprecated|archived|all, default verified)")
+	cmd.Flags().BoolVar(&includeUnreviewed, "include-unreviewed", false, "Also include memories awaiting review")
+	return cmd
+}
+
+func topicsCmd() *cobra.Command {
+	var robotMode bool
+	var statusFilter []string
+	var includeUnreviewed bool
+	var teamID string
+	var limit int
+	var k int
+	var minSize int
+	var useLLM bool
+	var asTags bool
+	var tagPrefix string
+
+	cmd := &cobra.Command{
+		Use:   "topics",
+		Short: "Discover what the brain covers by clustering memories",
+		Long: `Cluster memories into topics and report, for each one, its size, average
+decay score, dominant tags and representative memories.
+
+Memories are clustered over their embeddings when every memory has one, and
+over TF-IDF term vectors otherwise. Topics are named from their most salient
+terms, or by the local LLM with --llm.
+
+--as-tags writes each topic label back to its memories as a tag
+(default prefix "topic/"), in a single Dolt commit.
+
+Examples:
+  ami topics
+  ami topics --team AMI-Dev --k 12 --llm
+  ami topics --as-tags`,
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, err := os.Getwd()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if err := db.InitDB(repoPath); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			defer db.CloseDB()
+
+			statuses, err := parseStatusFilter(statusFilter, includeUnreviewed)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			opts := store.TopicOptions{
+				TeamID:   teamID,
+				Statuses: statuses,
+				Limit:    limit,
+				K:        k,
+				MinSize:  minSize,
+			}
+			if useLLM {
+				opts.Ollama = db.NewOllamaClientFromEnv()
+			}
+
+			report, err := store.DiscoverTopics(context.Background(), opts)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error discovering topics: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			tagged := 0
+			if asTags {
+				tagged, err = store.TagTopics(report.Topics, tagPrefix)
+				if err != nil {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Error tagging memories: %v\n", err)
+					}
+					os.Exit(1)
+				}
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status": "ok",
+					"report": report,
+					"tagged": tagged,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			if len(report.Topics) == 0 {
+				fmt.Printf("No topics found among %d memory(ies).\n", report.Total)
+				return
+			}
+
+			fmt.Printf("Topics (%d from %d memories, %s):\n\n", len(report.Topics), report.Total, report.Method)
+			for i, t := range report.Topics {
+				fmt.Printf("%d. %s (%d memories, avg decay %.2f)\n", i+1, t.Label, t.Size, t.AvgDecay)
+				if len(t.Terms) > 0 {
+					fmt.Printf("   Terms: %s\n", strings.Join(t.Terms, ", "))
+				}
+				if len(t.DominantTags) > 0 {
+					var tags []string
+					for _, tc := range t.DominantTags {
+						tags = append(tags, fmt.Sprintf("%s (%d)", tc.Tag, tc.Count))
+					}
+					fmt.Printf("   Tags:  %s\n", strings.Join(tags, ", "))
+				}
+				for _, r := range t.Representatives {
+					fmt.Printf("   - [%s] %s\n", r.ID[:8], r.Content)
+				}
+				fmt.Println()
+			}
+			if report.Skipped > 0 {
+				fmt.Printf("%d memory(ies) fell into clusters smaller than %d and were left out.\n", report.Skipped, minSize)
+			}
+			if asTags {
+				fmt.Printf("✓ Tagged %d memory(ies) with %s<label>\n", tagged, tagPrefix)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	cmd.Flags().StringVar(&teamID, "team", "", "Only cluster memories from this team")
+	cmd.Flags().IntVar(&limit, "limit", 2000, "Maximum memories to cluster")
+	cmd.Flags().IntVar(&k, "k", 0, "Number of topics (default: sqrt(memories/2))")
+	cmd.Flags().IntVar(&minSize, "min-size", 2, "Hide topics with fewer memories")
+	cmd.Flags().BoolVar(&useLLM, "llm", false, "Name topics with the local LLM (Ollama)")
+	cmd.Flags().BoolVar(&asTags, "as-tags", false, "Write topic labels back to memories as tags")
+	cmd.Flags().StringVar(&tagPrefix, "tag-prefix", "topic/", "Prefix for tags written by --as-tags")
+	cmd.Flags().StringSliceVar(&statusFilter, "status", []string{}, "Statuses to include (verified|under_review|deprecated|archived|all, default verified)")
+	cmd.Flags().BoolVar(&includeUnreviewed, "include-unreviewed", false, "Also include memories awaiting review")
+	return cmd
+}
+
+func statsCmd() *cobra.Command {
+	var robotMode bool
+	var statusFilter []string
+	var includeUnreviewed bool
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show memory database analytics",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			statuses, err := parseStatusFilter(statusFilter, includeUnreviewed)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			stats, err := store.GetMemoryStats(statuses)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting stats: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				jsonBytes, _ := json.MarshalIndent(stats, "", "  ")
+				fmt.Println(string(jsonBytes))
+			} else {
+				fmt.Println("AMI Memory Statistics")
+				fmt.Printf("Total Memories: %v\n", stats["total_memories"])
+				fmt.Println("\nDistribution by Category:")
+				dist := stats["distribution"].(map[string]int)
+				for cat, count := range dist {
+					fmt.Printf("- %-10s: %d\n", cat, count)
+				}
+				fmt.Println("\nMetrics:")
+				metrics := stats["metrics"].(map[string]interface{})
+				fmt.Printf("- Avg Priority:  %.2f\n", metrics["avg_priority"])
+				fmt.Printf("- Avg Access:    %.2f\n", metrics["avg_access_count"])
+				fmt.Printf("- Avg Decay Score: %.2f\n", metrics["avg_decay_score"])
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	cmd.Flags().StringSliceVar(&statusFilter, "status", []string{}, "Statuses to include (verified|under_review|deprecated|archived|all, default verified)")
+	cmd.Flags().BoolVar(&includeUnreviewed, "include-unreviewed", false, "Also include memories awaiting review")
+	return cmd
+}
+
+func contextCmd() *cobra.Command {
+	var robotMode bool
+	var statusFilter []string
+	var includeUnreviewed bool
+	var limit int
+	var tokenBudget int
+	var extendTTL string
+
+	cmd := &cobra.Command{
+		Use:   "context [task]",
+		Short: "Get optimal context for a specific task",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, err := os.Getwd()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if err := db.InitDB(repoPath); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			defer db.CloseDB()
+
+			statuses, err := parseStatusFilter(statusFilter, includeUnreviewed)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			task := ""
+			if len(args) > 0 {
+				task = args[0]
+			}
+
+			// Get context memories with budget
+			memories, err := store.GetContextMemories(task, limit, tokenBudget, statuses)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting context: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if extendTTL != "" {
+				extendExpiry(memories, extendTTL)
+			}
+
+			if robotMode {
+				// Robot Mode: Pure JSON
+				unreviewed := []string{}
+				for _, m := range memories {
+					if m.Status == models.StatusUnderReview {
+						unreviewed = append(unreviewed, m.ID)
+					}
+				}
+				result := map[string]interface{}{
+					"status":         "ok",
+					"task":           task,
+					"budget":         tokenBudget,
+					"memories":       memories,
+					"unreviewed_ids": unreviewed,
+				}
+				jsonBytes, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error formatting JSON: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Println(string(jsonBytes))
+			} else {
+				fmt.Printf("Optimized Context for Task: %s (Budget: %d tokens)\n\n", task, tokenBudget)
+				if len(memories) == 0 {
+					fmt.Println("No relevant memories found.")
+					return
+				}
+
+				for _, m := range memories {
+					switch m.Status {
+					case models.StatusUnderReview:
+						fmt.Printf("[%s] [UNREVIEWED] %s\n", m.Category, m.Content)
+					case models.StatusDeprecated:
+						fmt.Printf("[%s] [DEPRECATED] %s\n", m.Category, m.Content)
+					default:
+						fmt.Printf("[%s] %s\n", m.Category, m.Content)
+					}
+				}
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of task-related memories")
+	cmd.Flags().IntVar(&tokenBudget, "tokens", 4000, "Maximum token budget for context")
+	cmd.Flags().StringVar(&extendTTL, "extend-ttl", "", "Push expiring results out to now + TTL (e.g. 24h)")
+	cmd.Flags().StringSliceVar(&statusFilter, "status", []string{}, "Statuses to include (verified|under_review|deprecated|archived|all, default verified)")
+	cmd.Flags().BoolVar(&includeUnreviewed, "include-unreviewed", false, "Also include memories awaiting review")
+	return cmd
+}
+
+func promoteCmd() *cobra.Command {
+	var robotMode bool
+	var globalPath string
+	var autoPromote bool
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "promote [id]",
+		Short: "Promote a memory to the global team brain",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			if autoPromote {
+				// Auto-promote eligible memories
+				memories, err := store.FindAutoPromotionCandidates(5, 0.8)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error finding candidates: %v\n", err)
+					os.Exit(1)
+				}
+
+				if len(memories) == 0 {
+					fmt.Println("No memories meet the promotion criteria.")
+					return
+				}
+
+				if dryRun {
+					fmt.Printf("Found %d candidate(s) for promotion:\n", len(memories))
+					for _, m := range memories {
+						fmt.Printf("  - [%s] %s (access_count: %d, priority: %.2f, category: %s)\n",
+							m.ID[:8], m.Content, m.AccessCount, m.Priority, m.Category)
+					}
+					return
+				}
+
+				if !robotMode && !confirmAction(fmt.Sprintf("Promote %d memories to global brain?", len(memories))) {
+					fmt.Println("Promotion cancelled.")
+					return
+				}
+
+				for _, m := range memories {
+					if err := store.PromoteMemory(m.ID, globalPath); err != nil {
+						fmt.Fprintf(os.Stderr, "Error promoting %s: %v\n", m.ID, err)
+					} else {
+						fmt.Printf("✓ Promoted %s\n", m.ID[:8])
+					}
+				}
+			} else {
+				// Promote a specific memory
+				if len(args) == 0 {
+					fmt.Fprintln(os.Stderr, "Error: memory ID required (unless using --auto)")
+					os.Exit(1)
+				}
+				id := args[0]
+				if err := store.PromoteMemory(id, globalPath); err != nil {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Error promoting memory: %v\n", err)
+					}
+					os.Exit(1)
+				}
+
+				if robotMode {
+					fmt.Printf(`{"status":"ok","message":"promoted memory %s to global store"}`+"\n", id)
+				} else {
+					fmt.Printf("✓ Promoted memory %s to global brain (%s)\n", id, globalPath)
+				}
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	cmd.Flags().StringVar(&globalPath, "path", "/home/hargabyte/.ami/global", "Path to the global AMI store")
+	cmd.Flags().BoolVar(&autoPromote, "auto", false, "Auto-promote memories meeting criteria")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show candidates without promoting")
+	return cmd
+}
+
+func helpAgentsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "help-agents",
+		Short: "Output agent-optimized command reference",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Print(`# CHAOS Command Reference for AI Agents
+
+> Cognitive Heuristic Agent Operating System
+> This system manages your long-term memory using a versioned, metabolic architecture.
+> Use it to store facts, decisions, and patterns so you don't burn tokens re-learning them.
+
+## 🧠 Quick Start Workflow
+
+1. **Before a Task**: Get focused context
+   ` + "`" + `chaos context "your task description" --limit 5 --robot` + "`" + `
+
+2. **During a Task**: Store important discoveries
+   ` + "`" + `chaos add "Decision: use Go 1.22 for this module" --category working --tags technical` + "`" + `
+
+3. **During a Task**: Track decisions with linked memories
+   ` + "`" + `chaos decision track "Use binary embeddings" --task "v0.4.0" --memories "abc,def"` + "`" + `
+
+4. **After a Task**: Record decision outcomes
+   ` + "`" + `chaos decision outcome <id> --outcome 0.9 --feedback "Worked perfectly"` + "`" + `
+
+5. **After a Task**: Clean up and promote
+   ` + "`" + `chaos promote <memory-id>` + "`" + ` (if it's a permanent team truth)
+
+6. **Periodic Maintenance**: Reflect on episodic noise
+   ` + "`" + `chaos reflect --limit 10 --hours 24` + "`" + `
+
+## 📂 Memory Categories
+
+- **Core**: Foundational truths (User name, identity). Use for facts that NEVER change.
+- **Semantic**: Learned patterns/habits. Use for general knowledge gained over time.
+- **Working**: Task-specific context. Use for notes on the current session.
+- **Episodic**: Event logs. Use for "I did X at time Y".
+
+## 🎯 Decision Tracking (v0.5.0+)
+
+Track the decisions you make and learn from their outcomes:
+
+**Track a Decision:**
+   ` + "`" + `ami decision track "your decision text" --task "project-id" --memories "id1,id2"` + "`" + `
+
+**Record Outcome:**
+   ` + "`" + `ami decision outcome <decision-id> --outcome 0.8 --feedback "Notes"` + "`" + `
+
+**Credit Assignment:** Each outcome is split across the linked memories. Outcomes
+above 0.5 raise their priority and outcomes below 0.5 lower it (by up to 0.1 for
+a lone memory); re-recording replaces the earlier credit. See a memory's track
+record with ` + "`" + `ami show <id>` + "`" + `.
+
+## 🔍 Reflection (v0.5.0+)
+
+   ` + "`" + `ami reflect --limit 10 --hours 24 --apply --robot` + "`" + `
+
+Sends recent episodic memories to the local LLM and stages the suggested
+semantic facts as under_review, linked to the memories they came from.
+
+## 🤖 Robot Mode
+
+ALWAYS use the ` + "`" + `--robot` + "`" + ` flag for programmatic integration.
+It returns pure JSON to stdout.
+
+## 💡 Best Practices
+
+- **Atomic Memories**: One fact per memory. Don't mix user preferences with technical specs.
+- **Source Attribution**: Always use ` + "`" + `--source` + "`" + ` so future you knows WHY you believe a fact.
+- **Aggressive Tagging**: Use tags for project IDs and concepts to make filtering faster.
+- **Decision Tracking**: Link memories to decisions so successful choices reinforce useful knowledge.
+- **Regular Reflection**: Use ` + "`" + `ami reflect` + "`" + ` to convert episodic noise into semantic facts.
+`)
+		},
+	}
+}
+
+func deleteCmd() *cobra.Command {
+	var robotMode bool
+
+	cmd := &cobra.Command{
+		Use:   "delete [id]",
+		Short: "Delete a memory by ID",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, err := os.Getwd()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if err := db.InitDB(repoPath); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			defer db.CloseDB()
+
+			id := args[0]
+
+			// Delete the memory
+			if err := store.DeleteMemory(id); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error deleting memory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				fmt.Printf(`{"status":"ok","message":"deleted memory %s"}`+"\n", id)
+			} else {
+				fmt.Printf("✓ Deleted memory %s\n", id)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	return cmd
+}
+
+func tagsCmd() *cobra.Command {
+	var robotMode bool
+
+	cmd := &cobra.Command{
+		Use:   "tags",
+		Short: "List and reorganize tags",
+		Long: `List all unique tags, or reorganize the tag taxonomy.
+
+Tags are normalized on add (lowercase, spaces become dashes) and may be
+hierarchical: filtering by "security" also matches "security/auth".
+Aliases resolve to their target tag on add and in recall filters.
+
+Subcommands:
+  rename <old> <new>              - Rename a tag and everything beneath it
+  merge <tag...> --into <target>  - Fold tags into one, keeping them as aliases
+  alias <alias> <tag>             - Make <alias> resolve to <tag>
+  tree                            - Show the tag hierarchy with counts
+
+Every bulk rewrite is a single Dolt commit.
+
+Examples:
+  ami tags rename auth security/auth
+  ami tags merge sec infosec --into security
+  ami tags alias k8s kubernetes
+  ami tags tree`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, err := os.Getwd()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if err := db.InitDB(repoPath); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			defer db.CloseDB()
+
+			tags, err := store.ListTags()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error listing tags: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status": "ok",
+					"tags":   tags,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+			} else {
+				fmt.Println("Unique Tags:")
+				for _, tag := range tags {
+					fmt.Printf("- %s\n", tag)
+				}
+			}
+		},
+	}
+
+	var into string
+	var noAlias bool
+	var removeAlias bool
+
+	renameCmd := &cobra.Command{
+		Use:   "rename <old> <new>",
+		Short: "Rename a tag and its children on every memory",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			res, err := store.RenameTag(args[0], args[1])
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error renaming tag: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":   "ok",
+					"from":     store.NormalizeTag(args[0]),
+					"to":       store.NormalizeTag(args[1]),
+					"memories": res.Memories,
+					"aliases":  res.Aliases,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+			fmt.Printf("✓ Renamed %s to %s on %d memory(ies)\n", store.NormalizeTag(args[0]), store.NormalizeTag(args[1]), res.Memories)
+			if res.Aliases > 0 {
+				fmt.Printf("  %d alias(es) repointed\n", res.Aliases)
+			}
+		},
+	}
+
+	mergeCmd := &cobra.Command{
+		Use:   "merge <tag...> --into <target>",
+		Short: "Merge tags into one",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if into == "" {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"--into is required"}` + "\n")
+				} else {
+					fmt.Fprintln(os.Stderr, "Error: --into is required")
+				}
+				os.Exit(1)
+			}
+
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			res, err := store.MergeTags(args, into, !noAlias)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error merging tags: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":   "ok",
+					"into":     store.NormalizeTag(into),
+					"memories": res.Memories,
+					"aliases":  res.Aliases,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+			fmt.Printf("✓ Merged %s into %s on %d memory(ies)\n", strings.Join(args, ", "), store.NormalizeTag(into), res.Memories)
+			if !noAlias {
+				fmt.Println("  Old tags kept as aliases")
+			}
+		},
+	}
+	mergeCmd.Flags().StringVar(&into, "into", "", "Tag to merge into (required)")
+	mergeCmd.Flags().BoolVar(&noAlias, "no-alias", false, "Don't keep the merged tags as aliases")
+
+	aliasCmd := &cobra.Command{
+		Use:   "alias [<alias> <tag>]",
+		Short: "Add, remove or list tag aliases",
+		Long: `With two arguments, make <alias> resolve to <tag>. With --remove, delete
+an alias. With no arguments, list all aliases.`,
+		Args: cobra.MaximumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			var err error
+			switch {
+			case removeAlias && len(args) == 1:
+				err = store.RemoveTagAlias(args[0])
+			case !removeAlias && len(args) == 2:
+				err = store.AddTagAlias(args[0], args[1])
+			case !removeAlias && len(args) == 0:
+				aliases, err := store.LoadTagAliases()
+				if err != nil {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Error listing aliases: %v\n", err)
+					}
+					os.Exit(1)
+				}
+				if robotMode {
+					result := map[string]interface{}{
+						"status":  "ok",
+						"aliases": aliases,
+					}
+					jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+					fmt.Println(string(jsonBytes))
+					return
+				}
+				if len(aliases) == 0 {
+					fmt.Println("No tag aliases.")
+					return
+				}
+				names := make([]string, 0, len(aliases))
+				for a := range aliases {
+					names = append(names, a)
+				}
+				sort.Strings(names)
+				for _, a := range names {
+					fmt.Printf("%s -> %s\n", a, aliases[a])
+				}
+				return
+			default:
+				err = fmt.Errorf("usage: ami tags alias <alias> <tag> | --remove <alias> | (no args to list)")
+			}
+
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				fmt.Println(`{"status":"ok"}`)
+			} else if removeAlias {
+				fmt.Printf("✓ Removed alias %s\n", store.NormalizeTag(args[0]))
+			} else {
+				fmt.Printf("✓ %s now resolves to %s\n", store.NormalizeTag(args[0]), store.NormalizeTag(args[1]))
+			}
+		},
+	}
+	aliasCmd.Flags().BoolVar(&removeAlias, "remove", false, "Remove the given alias")
+
+	treeCmd := &cobra.Command{
+		Use:   "tree",
+		Short: "Show the tag hierarchy with memory counts",
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			tree, err := store.TagTree()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error building tag tree: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status": "ok",
+					"tree":   tree,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			if len(tree) == 0 {
+				fmt.Println("No tags.")
+				return
+			}
+			var printNode func(n *store.TagNode, depth int)
+			printNode = func(n *store.TagNode, depth int) {
+				line := fmt.Sprintf("%s%s (%d)", strings.Repeat("  ", depth), n.Name, n.Total)
+				if len(n.Aliases) > 0 {
+					line += fmt.Sprintf("  aka %s", strings.Join(n.Aliases, ", "))
+				}
+				fmt.Println(line)
+				for _, c := range n.Children {
+					printNode(c, depth+1)
+				}
+			}
+			for _, n := range tree {
+				printNode(n, 0)
+			}
+		},
+	}
+
+	cmd.AddCommand(renameCmd, mergeCmd, aliasCmd, treeCmd)
+	cmd.PersistentFlags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	return cmd
+}
+
+func enrichCmd() *cobra.Command {
+	var backfill bool
+	var teamID string
+	var batchSize int
+	var limit int
+	var all bool
+	var dryRun bool
+	var robotMode bool
+
+	cmd := &cobra.Command{
+		Use:   "enrich --backfill",
+		Short: "Tag and categorize existing memories with the local LLM",
+		Long: `Ask the local Ollama model to suggest tags, category and priority for
+existing memories. Tags are chosen from the tags already in use.
+
+Only untagged memories are processed unless --all is given. Suggested tags are
+added to existing ones; category only replaces the episodic default and
+priority only replaces the 0.5 default. Each batch is its own Dolt commit, so
+an interrupted run keeps its progress.
+
+Examples:
+  ami enrich --backfill --dry-run --limit 10
+  ami enrich --backfill --team AMI-Dev --batch-size 50`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !backfill {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"nothing to do; use --backfill"}` + "\n")
+				} else {
+					fmt.Fprintln(os.Stderr, "Error: nothing to do; use --backfill (new memories: ami add --enrich)")
+				}
+				os.Exit(1)
+			}
+
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			opts := store.EnrichOptions{
+				TeamID:    teamID,
+				BatchSize: batchSize,
+				Limit:     limit,
+				All:       all,
+				DryRun:    dryRun,
+				Ollama:    db.NewOllamaClientFromEnv(),
+			}
+			if !robotMode {
+				opts.Progress = func(done, total int) {
+					fmt.Fprintf(os.Stderr, "  enriched %d/%d\n", done, total)
+				}
+			}
+
+			results, err := store.BackfillEnrichment(context.Background(), opts)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error enriching memories: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			changed, failed := 0, 0
+			for _, r := range results {
+				if r.Changed {
+					changed++
+				}
+				if r.Error != "" {
+					failed++
+				}
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":  "ok",
+					"dry_run": dryRun,
+					"count":   len(results),
+					"changed": changed,
+					"failed":  failed,
+					"results": results,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			if len(results) == 0 {
+				fmt.Println("No memories to enrich.")
+				return
+			}
+
+			for _, r := range results {
+				switch {
+				case r.Error != "":
+					fmt.Printf("✗ [%s] %v\n", r.ID[:8], r.Error)
+				case r.Changed:
+					fmt.Printf("[%s] %s\n", r.ID[:8], r.Content)
+					fmt.Printf("   tags: %s | category: %s | priority: %.2f\n", strings.Join(r.Tags, ", "), r.Category, r.Priority)
+				}
+			}
+			if dryRun {
+				fmt.Printf("\nDry run: %d of %d memory(ies) would change (%d failed)\n", changed, len(results), failed)
+			} else {
+				fmt.Printf("\n✓ Enriched %d of %d memory(ies) (%d failed)\n", changed, len(results), failed)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&backfill, "backfill", false, "Enrich existing memories")
+	cmd.Flags().StringVar(&teamID, "team", "", "Only enrich memories from this team")
+	cmd.Flags().IntVar(&batchSize, "batch-size", 20, "Memories per Dolt commit")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum memories to process (0 for all)")
+	cmd.Flags().BoolVar(&all, "all", false, "Also enrich memories that already have tags")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show suggestions without writing")
+	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	return cmd
+}
+
+func checkpointCmd() *cobra.Command {
+	var name string
+	var ownerID string
+	var robotMode bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "checkpoint [description]",
+		Short: "Create a checkpoint before compression",
+		Long: `Create a named Dolt tag of the memory store as a safety net before context
+compression or risky bulk edits.
+
+Subcommands:
+  list            - List checkpoints
+  diff <name>     - Show memories changed since a checkpoint
+  restore <name>  - Restore every table to a checkpoint as a new commit`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, err := os.Getwd()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if err := db.InitDB(repoPath); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			defer db.CloseDB()
+
+			cp, err := store.CreateCheckpoint(name, strings.Join(args, " "), ownerID)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error creating checkpoint: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":     "ok",
+					"checkpoint": cp,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+			} else {
+				fmt.Printf("✓ Checkpoint %s created at %s\n", cp.Name, cp.Hash)
+				fmt.Printf("  Restore with: ami checkpoint restore %s\n", cp.Name)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "Checkpoint name (default: checkpoint-<timestamp>)")
+	cmd.Flags().StringVar(&ownerID, "owner", "system", "ID of the agent creating the checkpoint")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List checkpoints",
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			checkpoints, err := store.ListCheckpoints()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error listing checkpoints: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":      "ok",
+					"count":       len(checkpoints),
+					"checkpoints": checkpoints,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			if len(checkpoints) == 0 {
+				fmt.Println("No checkpoints found.")
+				return
+			}
+			fmt.Printf("Checkpoints (%d):\n\n", len(checkpoints))
+			for _, cp := range checkpoints {
+				fmt.Printf("%s (%s)\n", cp.Name, cp.Hash)
+				fmt.Printf("  Owner: %s | Created: %s\n", cp.Owner, cp.CreatedAt.Format("2006-01-02 15:04"))
+				if cp.Description != "" {
+					fmt.Printf("  %s\n", cp.Description)
+				}
+			}
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "diff <name>",
+		Short: "Show memories changed since a checkpoint",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			diffs, err := store.DiffCheckpoint(args[0])
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error diffing checkpoint: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":     "ok",
+					"checkpoint": args[0],
+					"count":      len(diffs),
+					"changes":    diffs,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			fmt.Printf("Changes since %s (%d):\n\n", args[0], len(diffs))
+			for _, d := range diffs {
+				switch d.DiffType {
+				case "added":
+					fmt.Printf("+ %s: %s\n", d.ID, d.ToContent)
+				case "removed":
+					fmt.Printf("- %s: %s\n", d.ID, d.FromContent)
+				default:
+					fmt.Printf("~ %s\n", d.ID)
+					if d.FromContent != d.ToContent {
+						fmt.Printf("    was: %s\n    now: %s\n", d.FromContent, d.ToContent)
+					}
+				}
+			}
+		},
+	})
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore <name>",
+		Short: "Restore every table to a checkpoint",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			if !robotMode && !yes && !confirmAction(fmt.Sprintf("Restore memories to checkpoint %s?", args[0])) {
+				fmt.Println("Restore cancelled.")
+				return
+			}
+
+			if err := store.RestoreCheckpoint(args[0]); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error restoring checkpoint: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				fmt.Printf(`{"status":"ok","message":"restored checkpoint %s"}`+"\n", args[0])
+			} else {
+				fmt.Printf("✓ Restored memories to checkpoint %s\n", args[0])
+			}
+		},
+	}
+	restoreCmd.Flags().BoolVar(&yes, "yes", false, "Skip confirmation")
+	cmd.AddCommand(restoreCmd)
+
+	cmd.PersistentFlags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	return cmd
+}
+
+func consolidateCmd() *cobra.Command {
+	var hours int
+	var limit int
+	var teamID string
+	var threshold float64
+	var lexicalThreshold float64
+	var minSize int
+	var demote bool
+	var dryRun bool
+	var robotMode bool
+
+	cmd := &cobra.Command{
+		Use:   "consolidate",
+		Short: "Consolidate episodic memories to semantic",
+		Long: `Cluster recent episodic memories by similarity and synthesize one semantic
+fact per cluster with the local Ollama model.
+
+New facts are staged as under_review and linked derived_from to their sources.
+Use --demote to halve the priority of the consolidated episodic memories, and
+--dry-run to preview clusters and facts without writing anything.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, err := os.Getwd()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if err := db.InitDB(repoPath); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			defer db.CloseDB()
+
+			clusters, err := store.ConsolidateMemories(context.Background(), store.ConsolidateOptions{
+				Hours:            hours,
+				Limit:            limit,
+				TeamID:           teamID,
+				Threshold:        threshold,
+				LexicalThreshold: lexicalThreshold,
+				MinClusterSize:   minSize,
+				Demote:           demote,
+				DryRun:           dryRun,
+				Ollama:           db.NewOllamaClientFromEnv(),
+			})
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error consolidating memories: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":   "ok",
+					"dry_run":  dryRun,
+					"count":    len(clusters),
+					"clusters": clusters,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			if len(clusters) == 0 {
+				fmt.Println("No clusters of episodic memories found to consolidate.")
+				return
+			}
+
+			if dryRun {
+				fmt.Printf("Dry run: %d cluster(s) would be consolidated\n", len(clusters))
+			} else {
+				fmt.Printf("✓ Consolidated %d cluster(s) into semantic facts (staged for review)\n", len(clusters))
+			}
+
+			for i, c := range clusters {
+				fmt.Printf("\n%d. %s\n", i+1, c.Fact)
+				if c.FactID != "" {
+					fmt.Printf("   Fact: %s (%s)\n", c.FactID, c.Action)
+				}
+				fmt.Printf("   From %d memories (%s, team: %s):\n", len(c.Sources), c.Method, c.TeamID)
+				for _, m := range c.Sources {
+					fmt.Printf("   - [%s] %s\n", m.ID[:8], m.Content)
+				}
+			}
+		},
+	}
+	cmd.Flags().IntVar(&hours, "hours", 168, "Hours to look back")
+	cmd.Flags().IntVar(&limit, "limit", 200, "Maximum number of episodic memories to cluster")
+	cmd.Flags().StringVar(&teamID, "team", "", "Only consolidate memories from this team")
+	cmd.Flags().Float64Var(&threshold, "threshold", 0.8, "Minimum embedding similarity to join a cluster")
+	cmd.Flags().Float64Var(&lexicalThreshold, "lexical-threshold", 0.35, "Minimum term overlap when embeddings are missing")
+	cmd.Flags().IntVar(&minSize, "min-size", 2, "Minimum cluster size to synthesize a fact")
+	cmd.Flags().BoolVar(&demote, "demote", false, "Halve the priority of consolidated source memories")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview clusters and facts without writing")
+	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	return cmd
+}
+
+func decisionCmd() *cobra.Command {
+	var taskID string
+	var ownerID string
+	var memoryIDsStr string
+	var outcomeStr string
+	var feedback string
+	var rationale string
+	var alternatives []string
+	var proposed bool
+	var reason string
+	var statusFilter string
+	var reportFormat string
+	var reportSince string
+	var pendingDays int
+	var topMemories int
+	var minDecisions int
+	var confidence float64
+	var bins int
+	var calibrationOwner string
+	var calibrationSince string
+	var gitCommit string
+	var noGit bool
+	var files []string
+	var robotMode bool
+
+	cmd := &cobra.Command{
+		Use:   "decision [action]",
+		Short: "Track decisions and their outcomes",
+		Long: `Track decisions and reinforce memories that lead to good outcomes.
+
+Actions:
+  track [decision]      - Track a new decision with linked memories
+  outcome <id>          - Record the outcome of a decision (0.0 to 1.0)
+  list [task_id]        - List all decisions, optionally filtered by task
+  show <id>             - Show a decision, its alternatives and its timeline
+  activate <id>         - Put a proposed decision into effect
+  revert <id>           - Mark a decision as undone
+  supersede <old> <new> - Replace a decision with a newer one
+  report                - Outcome analytics for retrospectives
+  calibration           - How well stated confidence predicted outcomes, per owner
+  for-file <path>       - Decisions that touched a file or directory
+
+A decision is proposed, active, reverted or superseded. Its outcome stays
+pending until one is recorded, so an outcome of 0.0 is a real result.
+
+Examples:
+  ami decision track "Use binary embeddings" --task "v0.4.0" --memories "abc,def" \
+    --rationale "Halves index size" --alternative "float16: still too large"
+  ami decision outcome abc-123 --outcome 0.9 --feedback "Worked perfectly"
+  ami decision revert abc-123 --reason "Recall dropped on long queries"
+  ami decision supersede abc-123 def-456
+  ami decision report --since 30d --format markdown
+  ami decision track "Cache embeddings" --owner agent-7 --confidence 0.7
+  ami decision calibration --owner agent-7
+  ami decision track "Retry uploads" --git-commit 3f2a9c1 --files internal/upload.go
+  ami decision for-file internal/upload.go
+  ami decision list v0.4.0`,
+	}
+
+	trackCmd := &cobra.Command{
+		Use:   "track [decision]",
+		Short: "Track a new decision",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, err := os.Getwd()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if err := db.InitDB(repoPath); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			defer db.CloseDB()
+
+			// Parse memory IDs
+			var memoryIDs []string
+			if memoryIDsStr != "" {
+				memoryIDs = strings.Split(memoryIDsStr, ",")
+				// Trim spaces from each ID
+				for i, id := range memoryIDs {
+					memoryIDs[i] = strings.TrimSpace(id)
+				}
+			}
+
+			var alts []store.Alternative
+			for _, a := range alternatives {
+				if alt := store.ParseAlternative(a); alt.Option != "" {
+					alts = append(alts, alt)
+				}
+			}
+
+			status := store.DecisionActive
+			if proposed {
+				status = store.DecisionProposed
+			}
+
+			var predicted *float64
+			if cmd.Flags().Changed("confidence") {
+				if confidence < 0.0 || confidence > 1.0 {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"confidence must be between 0.0 and 1.0"}` + "\n")
+					} else {
+						fmt.Fprintf(os.Stderr, "Error: confidence must be between 0.0 and 1.0\n")
+					}
+					os.Exit(1)
+				}
+				predicted = &confidence
+			}
+
+			// Link the code change: an explicit commit (and, unless --files is
+			// given, the files it changed) or the working tree's HEAD
+			wd, _ := os.Getwd()
+			var gitHash string
+			if gitCommit != "" {
+				gitHash, err = db.GitResolveCommit(wd, gitCommit)
+				if err != nil {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					}
+					os.Exit(1)
+				}
+			} else if !noGit {
+				// Not being in a git repo is fine; the decision just isn't linked
+				gitHash, _ = db.GitHead(wd)
+			}
+
+			// User paths are relative to the cwd; the commit's own files are
+			// already relative to the repo root
+			var relFiles []string
+			if gitCommit != "" && len(files) == 0 {
+				relFiles, _ = db.GitCommitFiles(wd, gitHash)
+			}
+			for _, f := range files {
+				rel, err := db.GitRelativePath(wd, f)
+				if err != nil {
+					// Outside a git repo, keep the path as given
+					rel = filepath.ToSlash(filepath.Clean(f))
+				}
+				relFiles = append(relFiles, rel)
+			}
+
+			// Track the decision
+			decisionText := strings.Join(args, " ")
+			decision, err := store.TrackDecisionWithParams(store.TrackParams{
+				TaskID:       taskID,
+				OwnerID:      ownerID,
+				MemoryIDs:    memoryIDs,
+				DecisionText: decisionText,
+				Status:       status,
+				Rationale:    rationale,
+				Alternatives: alts,
+				Confidence:   predicted,
+				GitCommit:    gitHash,
+				Files:        relFiles,
+			})
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error tracking decision: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				jsonBytes, _ := json.MarshalIndent(decision, "", "  ")
+				fmt.Println(string(jsonBytes))
+			} else {
+				fmt.Printf("✓ Decision tracked: %s\n", decision.ID)
+				fmt.Printf("  Task: %s\n", decision.TaskID)
+				fmt.Printf("  Commit: %s\n", decision.CommitHash)
+				if decision.GitCommit != "" {
+					fmt.Printf("  Git commit: %s\n", decision.GitCommit)
+				}
+				if len(decision.Files) > 0 {
+					fmt.Printf("  Files: %s\n", strings.Join(decision.Files, ", "))
+				}
+				fmt.Printf("  Text: %s\n", decision.DecisionText)
+				fmt.Printf("  Status: %s\n", decision.Status)
+				if decision.Confidence != nil {
+					fmt.Printf("  Predicted success: %.2f\n", *decision.Confidence)
+				}
+				if len(decision.MemoryIDs) > 0 {
+					fmt.Printf("  Linked memories: %d\n", len(decision.MemoryIDs))
+				}
+				if len(decision.Alternatives) > 0 {
+					fmt.Printf("  Alternatives considered: %d\n", len(decision.Alternatives))
+				}
+			}
+		},
+	}
+	trackCmd.Flags().StringVar(&taskID, "task", "", "Task ID")
+	trackCmd.Flags().StringVar(&ownerID, "owner", "system", "ID of the agent making the decision")
+	trackCmd.Flags().StringVar(&memoryIDsStr, "memories", "", "Comma-separated memory IDs")
+	trackCmd.Flags().StringVar(&rationale, "rationale", "", "Why this option was chosen")
+	trackCmd.Flags().StringArrayVar(&alternatives, "alternative", []string{}, "A rejected option as \"option: reason\" (repeatable)")
+	trackCmd.Flags().BoolVar(&proposed, "proposed", false, "Record as proposed rather than active")
+	trackCmd.Flags().StringVar(&gitCommit, "git-commit", "", "Project git commit the decision produced (default: the working tree's HEAD)")
+	trackCmd.Flags().BoolVar(&noGit, "no-git", false, "Don't link the working tree's git HEAD")
+	trackCmd.Flags().StringSliceVar(&files, "files", []string{}, "Files the decision touched (default with --git-commit: the files that commit changed)")
+	trackCmd.Flags().Float64Var(&confidence, "confidence", 0, "Predicted probability of success (0.0-1.0), scored by 'decision calibration'")
+
+	outcomeCmd := &cobra.Command{
+		Use:   "outcome <decision_id>",
+		Short: "Record the outcome of a decision",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, err := os.Getwd()
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if err := db.InitDB(repoPath); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			defer db.CloseDB()
+
+			decisionID := args[0]
+			var outcome float64
+			_, err = fmt.Sscanf(outcomeStr, "%f", &outcome)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"invalid outcome: %v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: invalid outcome value\n")
+				}
+				os.Exit(1)
+			}
+
+			// Validate outcome range
+			if outcome < 0.0 || outcome > 1.0 {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"outcome must be between 0.0 and 1.0"}`+"\n")
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: outcome must be between 0.0 and 1.0\n")
+				}
+				os.Exit(1)
+			}
+
+			// Record the outcome
+			err = store.RecordOutcome(decisionID, outcome, feedback)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error recording outcome: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				fmt.Printf(`{"status":"success","decision_id":"%s","outcome":%f}`+"\n", decisionID, outcome)
+			} else {
+				fmt.Printf("✓ Outcome recorded: %.2f\n", outcome)
+				switch {
+				case outcome > 0.5:
+					fmt.Printf("  → Linked memories credited with a success.\n")
+				case outcome < 0.5:
+					fmt.Printf("  → Linked memories credited with a failure.\n")
+				}
+			}
+		},
+	}
+	outcomeCmd.Flags().StringVar(&outcomeStr, "outcome", "", "Outcome value (0.0 to 1.0)")
+	outcomeCmd.Flags().StringVar(&feedback, "feedback", "", "Optional feedback text")
+	outcomeCmd.MarkFlagRequired("outcome")
+
+	listCmd := &cobra.Command{
+		Use:   "list [task_id]",
+		Short: "List decisions",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize database
+			repoPath, err := os.Getwd()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := db.InitDB(repoPath); err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+				os.Exit(1)
+			}
+			defer db.CloseDB()
+
+			listTaskID := ""
+			if len(args) > 0 {
+				listTaskID = args[0]
+			}
+
+			decisions, err := store.ListDecisions(listTaskID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing decisions: %v\n", err)
+				os.Exit(1)
+			}
+
+			if statusFilter != "" {
+				if !store.DecisionStatus(statusFilter).IsValid() && statusFilter != "pending" {
+					fmt.Fprintf(os.Stderr, "Error: invalid status '%s'. Must be one of: proposed, active, reverted, superseded, pending\n", statusFilter)
+					os.Exit(1)
+				}
+				filtered := decisions[:0]
+				for _, d := range decisions {
+					if string(d.Status) == statusFilter || (statusFilter == "pending" && d.Pending()) {
+						filtered = append(filtered, d)
+					}
+				}
+				decisions = filtered
+			}
+
+			if robotMode {
+				jsonBytes, _ := json.MarshalIndent(decisions, "", "  ")
+				fmt.Println(string(jsonBytes))
+			} else {
+				if len(decisions) == 0 {
+					fmt.Println("No decisions found.")
+					return
+				}
+
+				for _, d := range decisions {
+					fmt.Printf("\n%s [%s]\n", d.ID, d.Status)
+					fmt.Printf("  Task: %s\n", d.TaskID)
+					fmt.Printf("  Commit: %s\n", d.CommitHash)
+					fmt.Printf("  Decision: %s\n", d.DecisionText)
+					fmt.Printf("  Outcome: %s\n", formatOutcome(d.Outcome))
+					if d.Feedback != "" {
+						fmt.Printf("  Feedback: %s\n", d.Feedback)
+					}
+					if len(d.MemoryIDs) > 0 {
+						fmt.Printf("  Linked memories: %d\n", len(d.MemoryIDs))
+					}
+				}
+			}
+		},
+	}
+	listCmd.Flags().StringVar(&statusFilter, "status", "", "Only show decisions with this status (proposed|active|reverted|superseded|pending)")
+
+	showCmd := &cobra.Command{
+		Use:   "show <decision_id>",
+		Short: "Show a decision with its alternatives and timeline",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			decision, err := store.GetDecision(args[0])
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error retrieving decision: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			timeline, err := store.GetDecisionTimeline(decision.ID)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error loading timeline: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			replaced, err := store.GetSupersededDecisions(decision.ID)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error loading superseded decisions: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":     "ok",
+					"decision":   decision,
+					"supersedes": replaced,
+					"timeline":   timeline,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			fmt.Printf("%s [%s]\n", decision.ID, decision.Status)
+			fmt.Printf("  Decision: %s\n", decision.DecisionText)
+			fmt.Printf("  Task: %s | Owner: %s\n", decision.TaskID, decision.OwnerID)
+			fmt.Printf("  Created: %s (commit %s)\n", decision.CreatedAt.Format("2006-01-02 15:04"), decision.CommitHash)
+			if decision.GitCommit != "" {
+				fmt.Printf("  Git commit: %s\n", decision.GitCommit)
+			}
+			if len(decision.Files) > 0 {
+				fmt.Printf("  Files: %s\n", strings.Join(decision.Files, ", "))
+			}
+			if decision.Confidence != nil {
+				fmt.Printf("  Predicted success: %.2f\n", *decision.Confidence)
+			}
+			fmt.Printf("  Outcome: %s\n", formatOutcome(decision.Outcome))
+			if decision.Feedback != "" {
+				fmt.Printf("  Feedback: %s\n", decision.Feedback)
+			}
+			if decision.Rationale != "" {
+				fmt.Printf("  Rationale: %s\n", decision.Rationale)
+			}
+			if decision.StatusReason != "" {
+				fmt.Printf("  Reason for %s: %s\n", decision.Status, decision.StatusReason)
+			}
+			if decision.SupersededBy != "" {
+				fmt.Printf("  Superseded by: %s\n", decision.SupersededBy)
+			}
+			for _, r := range replaced {
+				fmt.Printf("  Supersedes: %s (%s)\n", r.ID, r.DecisionText)
+			}
+
+			if len(decision.Alternatives) > 0 {
+				fmt.Println("\nAlternatives considered:")
+				for _, a := range decision.Alternatives {
+					if a.Reason != "" {
+						fmt.Printf("  - %s: rejected because %s\n", a.Option, a.Reason)
+					} else {
+						fmt.Printf("  - %s\n", a.Option)
+					}
+				}
+			}
+
+			if len(decision.MemoryIDs) > 0 {
+				fmt.Println("\nInformed by:")
+				for _, id := range decision.MemoryIDs {
+					if m, err := store.GetMemoryByID(id); err == nil {
+						fmt.Printf("  - %s %s\n", id, m.Content)
+					} else {
+						fmt.Printf("  - %s (missing)\n", id)
+					}
+				}
+			}
+
+			if len(timeline) > 0 {
+				fmt.Println("\nTimeline:")
+				for _, e := range timeline {
+					line := fmt.Sprintf("  %s  %-10s outcome %-7s", e.Date.Format("2006-01-02 15:04"), e.Status, formatOutcome(e.Outcome))
+					if e.Message != "" {
+						line += "  " + e.Message
+					}
+					fmt.Println(line)
+				}
+			}
+		},
+	}
+
+	activateCmd := &cobra.Command{
+		Use:   "activate <decision_id>",
+		Short: "Put a proposed decision into effect",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			if err := store.ActivateDecision(args[0]); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error activating decision: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				fmt.Printf(`{"status":"ok","decision_id":"%s","decision_status":"active"}`+"\n", args[0])
+			} else {
+				fmt.Printf("✓ Decision %s is now active\n", args[0])
+			}
+		},
+	}
+
+	revertCmd := &cobra.Command{
+		Use:   "revert <decision_id>",
+		Short: "Mark a decision as reverted",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			if err := store.RevertDecision(args[0], reason); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error reverting decision: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				fmt.Printf(`{"status":"ok","decision_id":"%s","decision_status":"reverted"}`+"\n", args[0])
+			} else {
+				fmt.Printf("✓ Decision %s reverted\n", args[0])
+			}
+		},
+	}
+	revertCmd.Flags().StringVar(&reason, "reason", "", "Why the decision was reverted")
+
+	supersedeDecisionCmd := &cobra.Command{
+		Use:   "supersede <old_id> <new_id>",
+		Short: "Replace a decision with a newer one",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			if err := store.SupersedeDecision(args[0], args[1], reason); err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error superseding decision: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				fmt.Printf(`{"status":"ok","decision_id":"%s","superseded_by":"%s"}`+"\n", args[0], args[1])
+			} else {
+				fmt.Printf("✓ Decision %s superseded by %s\n", args[0], args[1])
+			}
+		},
+	}
+	supersedeDecisionCmd.Flags().StringVar(&reason, "reason", "", "Why the decision was replaced")
+
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Summarize decision outcomes for a retrospective",
+		Long: `Summarize tracked decisions: outcome distributions per task, per owner and
+per tag (from the memories behind each decision), the memories most associated
+with high and low outcomes, and decisions still pending after --pending-days.
+
+Examples:
+  ami decision report
+  ami decision report --since 7d --format markdown > retro.md
+  ami decision report --robot`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := store.ReportOptions{
+				PendingDays:  pendingDays,
+				TopMemories:  topMemories,
+				MinDecisions: minDecisions,
+			}
+			if reportSince != "" {
+				since, err := store.ParseTTL(reportSince)
+				if err != nil {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"invalid --since: %v"}`+"\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Error: invalid --since: %v\n", err)
+					}
+					os.Exit(1)
+				}
+				opts.Since = since
+			}
+
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			report, err := store.BuildDecisionReport(opts)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error building report: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status": "ok",
+					"report": report,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			if err := store.WriteDecisionReport(report, reportFormat, os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	reportCmd.Flags().StringVar(&reportFormat, "format", "table", "Output format (table|markdown)")
+	reportCmd.Flags().StringVar(&reportSince, "since", "", "Only decisions tracked within this window, e.g. 30d or 72h")
+	reportCmd.Flags().IntVar(&pendingDays, "pending-days", 14, "List decisions pending an outcome for at least this many days")
+	reportCmd.Flags().IntVar(&topMemories, "top", 5, "Memories to list at each end of the outcome range")
+	reportCmd.Flags().IntVar(&minDecisions, "min-decisions", 2, "Decisions with outcomes a memory needs to be ranked")
+
+	calibrationCmd := &cobra.Command{
+		Use:   "calibration",
+		Short: "Score stated confidence against outcomes, per owner",
+		Long: `Compare the confidence each owner stated when tracking a decision
+(--confidence) with the outcome recorded later.
+
+For every owner this prints the Brier score (mean squared gap between
+confidence and outcome; 0 is perfect, 0.25 is what always saying 0.5 earns on
+pass/fail outcomes), the bias (positive means over-confident) and a reliability
+curve: for each confidence band, the mean confidence against the mean outcome.
+A well-calibrated owner's curve follows the diagonal.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := store.CalibrationOptions{OwnerID: calibrationOwner, Bins: bins}
+			if calibrationSince != "" {
+				since, err := store.ParseTTL(calibrationSince)
+				if err != nil {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"invalid --since: %v"}`+"\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Error: invalid --since: %v\n", err)
+					}
+					os.Exit(1)
+				}
+				opts.Since = since
+			}
+
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			results, err := store.CalibrateOwners(opts)
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error computing calibration: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status": "ok",
+					"count":  len(results),
+					"owners": results,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			if len(results) == 0 {
+				fmt.Println("No decisions with both a stated confidence and an outcome.")
+				return
+			}
+
+			for i, c := range results {
+				if i > 0 {
+					fmt.Println()
+				}
+				verdict := "well calibrated"
+				switch {
+				case c.Bias > 0.1:
+					verdict = "over-confident"
+				case c.Bias < -0.1:
+					verdict = "under-confident"
+				}
+				fmt.Printf("%s: Brier %.3f over %d decision(s), %s (predicted %.2f, actual %.2f)\n",
+					c.OwnerID, c.Brier, c.Decisions, verdict, c.MeanConfidence, c.MeanOutcome)
+				for _, b := range c.Curve {
+					fmt.Printf("  %.1f-%.1f  n=%-3d predicted %.2f  actual %.2f  %s\n",
+						b.Lower, b.Upper, b.Count, b.MeanConfidence, b.MeanOutcome,
+						strings.Repeat("█", int(b.MeanOutcome*20+0.5)))
+				}
+			}
+		},
+	}
+	calibrationCmd.Flags().StringVar(&calibrationOwner, "owner", "", "Only this owner")
+	calibrationCmd.Flags().IntVar(&bins, "bins", 5, "Number of confidence bands in the reliability curve")
+	calibrationCmd.Flags().StringVar(&calibrationSince, "since", "", "Only decisions tracked within this window, e.g. 90d")
+
+	forFileCmd := &cobra.Command{
+		Use:   "for-file <path>",
+		Short: "List decisions that touched a file or directory",
+		Long: `List decisions recorded with the file (via --files, or a directory above
+it) and decisions whose git commit changed it, according to the local git
+history. Paths are resolved against the git repository in the current
+directory, so this works from any subdirectory and offline.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			wd, _ := os.Getwd()
+			db.InitDB(wd)
+			defer db.CloseDB()
+
+			decisions, err := store.ListDecisionsForFile(wd, args[0])
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error listing decisions: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":    "ok",
+					"path":      args[0],
+					"count":     len(decisions),
+					"decisions": decisions,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			if len(decisions) == 0 {
+				fmt.Printf("No decisions touched %s.\n", args[0])
+				return
+			}
+			fmt.Printf("Decisions that touched %s (%d):\n", args[0], len(decisions))
+			for _, d := range decisions {
+				commit := d.GitCommit
+				if len(commit) > 10 {
+					commit = commit[:10]
+				}
+				fmt.Printf("\n%s [%s] %s\n", d.ID, d.Status, d.CreatedAt.Format("2006-01-02"))
+				fmt.Printf("  %s\n", d.DecisionText)
+				fmt.Printf("  Task: %s | Owner: %s | Outcome: %s", d.TaskID, d.OwnerID, formatOutcome(d.Outcome))
+				if commit != "" {
+					fmt.Printf(" | Git: %s", commit)
+				}
+				fmt.Println()
+			}
+		},
+	}
+
+	cmd.AddCommand(trackCmd)
+	cmd.AddCommand(outcomeCmd)
+	cmd.AddCommand(listCmd)
+	cmd.AddCommand(showCmd)
+	cmd.AddCommand(activateCmd)
+	cmd.AddCommand(revertCmd)
+	cmd.AddCommand(supersedeDecisionCmd)
+	cmd.AddCommand(reportCmd)
+	cmd.AddCommand(calibrationCmd)
+	cmd.AddCommand(forFileCmd)
+	cmd.PersistentFlags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+
+	return cmd
+}
+
+// formatOutcome shows a recorded outcome, or pending if there is none yet
+func formatOutcome(outcome *float64) string {
+	if outcome == nil {
+		return "pending"
+	}
+	return fmt.Sprintf("%.2f", *outcome)
+}
+
+func reflectCmd() *cobra.Command {
+	var hours int
+	var limit int
+	var ownerID string
+	var apply bool
+	var robotMode bool
 
 	cmd := &cobra.Command{
-		Use:   "context [task]",
-		Short: "Get optimal context for a specific task",
+		Use:   "reflect",
+		Short: "Reflect on episodic memories and suggest synthesis",
+		Long: `Reflect on recent episodic memories and suggest semantic synthesis.
+The window of memories is sent to the local Ollama model, which proposes
+semantic facts together with the memory IDs they were derived from.
+
+Without --apply the suggestions are only shown. With --apply they are staged
+as under_review semantic memories linked derived_from to their sources.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			// Initialize database
 			repoPath, err := os.Getwd()
@@ -825,269 +3663,604 @@ func contextCmd() *cobra.Command {
 			}
 			defer db.CloseDB()
 
-			task := ""
-			if len(args) > 0 {
-				task = args[0]
+			// Calculate the time threshold
+			sinceTime := time.Now().Add(-time.Duration(hours) * time.Hour)
+
+			// Get episodic memories from the last N hours
+			memories, err := store.CatchupMemories(store.CatchupOptions{
+				Limit:    limit,
+				Category: "episodic",
+				Since:    sinceTime.Format("2006-01-02 15:04:05"),
+			})
+
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error fetching memories: %v\n", err)
+				}
+				os.Exit(1)
 			}
 
-			// Get context memories with budget
-			memories, err := store.GetContextMemories(task, limit, tokenBudget)
+			if len(memories) == 0 {
+				if robotMode {
+					fmt.Println(`{"status":"ok","count":0,"facts":[]}`)
+				} else {
+					fmt.Println("No episodic memories found for reflection.")
+				}
+				return
+			}
+
+			if !robotMode {
+				fmt.Printf("🤔 Reflecting on %d episodic memories from the last %d hour(s)\n\n", len(memories), hours)
+			}
+
+			facts, err := store.ReflectOnMemories(context.Background(), db.NewOllamaClientFromEnv(), memories)
 			if err != nil {
 				if robotMode {
 					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
 				} else {
-					fmt.Fprintf(os.Stderr, "Error getting context: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Error reflecting: %v\n", err)
 				}
 				os.Exit(1)
 			}
 
+			if apply {
+				if err := store.StageSuggestedFacts(facts, ownerID); err != nil {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Error staging facts: %v\n", err)
+					}
+					os.Exit(1)
+				}
+			}
+
 			if robotMode {
-				// Robot Mode: Pure JSON
 				result := map[string]interface{}{
-					"status":   "ok",
-					"task":     task,
-					"budget":   tokenBudget,
-					"memories": memories,
-				}
-				jsonBytes, err := json.MarshalIndent(result, "", "  ")
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error formatting JSON: %v\n", err)
-					os.Exit(1)
+					"status":    "ok",
+					"applied":   apply,
+					"reflected": len(memories),
+					"count":     len(facts),
+					"facts":     facts,
 				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
 				fmt.Println(string(jsonBytes))
-			} else {
-				fmt.Printf("Optimized Context for Task: %s (Budget: %d tokens)\n\n", task, tokenBudget)
-				if len(memories) == 0 {
-					fmt.Println("No relevant memories found.")
-					return
-				}
+				return
+			}
 
-				for _, m := range memories {
-					fmt.Printf("[%s] %s\n", m.Category, m.Content)
+			if len(facts) == 0 {
+				fmt.Println("No semantic facts suggested.")
+				return
+			}
+
+			for i, f := range facts {
+				fmt.Printf("Fact %d: %s\n", i+1, f.Content)
+				fmt.Printf("   Related: %s\n", strings.Join(f.RelatedIDs, ", "))
+				if f.FactID != "" {
+					fmt.Printf("   Staged as %s (%s, under review)\n", f.FactID, f.Action)
 				}
 			}
+
+			if !apply {
+				fmt.Println("\nRun again with --apply to stage these facts for review.")
+			}
 		},
 	}
+	cmd.Flags().IntVar(&hours, "hours", 24, "Hours to look back")
+	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of memories to reflect on")
+	cmd.Flags().StringVar(&ownerID, "owner", "system", "Owner of the staged facts")
+	cmd.Flags().BoolVar(&apply, "apply", false, "Stage suggested facts as under_review memories")
 	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
-	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of task-related memories")
-	cmd.Flags().IntVar(&tokenBudget, "tokens", 4000, "Maximum token budget for context")
+
 	return cmd
 }
 
-func promoteCmd() *cobra.Command {
-	var robotMode bool
-	var globalPath string
-	var autoPromote bool
-	var dryRun bool
-
+func conflictCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "promote [id]",
-		Short: "Promote a memory to the global team brain",
-		Args:  cobra.MaximumNArgs(1),
+		Use:   "conflict",
+		Short: "Detect and resolve conflicting memories",
+		Long: `Detect memories that may contradict each other using semantic similarity.
+This helps maintain team consensus across multiple agents.`,
+	}
+
+	var strategy string
+	var resolveRobot bool
+
+	resolveCmd := &cobra.Command{
+		Use:   "resolve <id1> <id2>",
+		Short: "Resolve a conflict between two memories",
+		Long: `Resolve a conflict between two memories.
+
+Strategies:
+  keep-first   - Keep <id1>, deprecate <id2>
+  keep-second  - Keep <id2>, deprecate <id1>
+  merge        - Combine both facts into <id1>, deprecate <id2>
+  llm-merge    - Ask the local Ollama model to write one coherent fact into <id1>
+  keep-both    - Dismiss the conflict without changing either memory
+
+The surviving memory gets a "supersedes" link to the deprecated one.
+Without --strategy, the choice is read interactively from stdin.`,
+		Args: cobra.ExactArgs(2),
 		Run: func(cmd *cobra.Command, args []string) {
-			// Initialize database
 			repoPath, _ := os.Getwd()
 			db.InitDB(repoPath)
 			defer db.CloseDB()
 
-			if autoPromote {
-				// Auto-promote eligible memories
-				memories, err := store.FindAutoPromotionCandidates(5, 0.8)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error finding candidates: %v\n", err)
-					os.Exit(1)
-				}
+			id1 := args[0]
+			id2 := args[1]
 
-				if len(memories) == 0 {
-					fmt.Println("No memories meet the promotion criteria.")
-					return
+			if strategy == "" {
+				if resolveRobot {
+					fmt.Printf(`{"status":"error","message":"--strategy is required in robot mode"}` + "\n")
+					os.Exit(1)
 				}
 
-				if dryRun {
-					fmt.Printf("Found %d candidate(s) for promotion:\n", len(memories))
-					for _, m := range memories {
-						fmt.Printf("  - [%s] %s (access_count: %d, priority: %.2f, category: %s)\n",
-							m.ID[:8], m.Content, m.AccessCount, m.Priority, m.Category)
-					}
-					return
+				// Get both memories
+				m1, err := store.GetMemoryByID(id1)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error fetching memory %s: %v\n", id1, err)
+					os.Exit(1)
 				}
-
-				if !robotMode && !confirmAction(fmt.Sprintf("Promote %d memories to global brain?", len(memories))) {
-					fmt.Println("Promotion cancelled.")
-					return
+				m2, err := store.GetMemoryByID(id2)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error fetching memory %s: %v\n", id2, err)
+					os.Exit(1)
 				}
 
-				for _, m := range memories {
-					if err := store.PromoteMemory(m.ID, globalPath); err != nil {
-						fmt.Fprintf(os.Stderr, "Error promoting %s: %v\n", m.ID, err)
-					} else {
-						fmt.Printf("✓ Promoted %s\n", m.ID[:8])
-					}
+				fmt.Println("Memory 1:")
+				fmt.Printf("  ID: %s\n", m1.ID)
+				fmt.Printf("  Content: %s\n", m1.Content)
+				fmt.Printf("  Category: %s\n", m1.Category)
+
+				fmt.Println("\nMemory 2:")
+				fmt.Printf("  ID: %s\n", m2.ID)
+				fmt.Printf("  Content: %s\n", m2.Content)
+				fmt.Printf("  Category: %s\n", m2.Category)
+
+				fmt.Println("\nResolution options:")
+				fmt.Println("1. Keep Memory 1 (deprecate Memory 2)")
+				fmt.Println("2. Keep Memory 2 (deprecate Memory 1)")
+				fmt.Println("3. Merge into Memory 1")
+				fmt.Println("4. Merge into Memory 1 using the local LLM")
+				fmt.Println("5. Keep both (dismiss conflict)")
+
+				fmt.Print("\nSelect option [1-5]: ")
+				var choice int
+				fmt.Scanln(&choice)
+
+				choices := map[int]store.ResolutionStrategy{
+					1: store.StrategyKeepFirst,
+					2: store.StrategyKeepSecond,
+					3: store.StrategyMerge,
+					4: store.StrategyLLMMerge,
+					5: store.StrategyKeepBoth,
 				}
-			} else {
-				// Promote a specific memory
-				if len(args) == 0 {
-					fmt.Fprintln(os.Stderr, "Error: memory ID required (unless using --auto)")
+				chosen, ok := choices[choice]
+				if !ok {
+					fmt.Println("Invalid choice.")
 					os.Exit(1)
 				}
-				id := args[0]
-				if err := store.PromoteMemory(id, globalPath); err != nil {
-					if robotMode {
-						fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
-					} else {
-						fmt.Fprintf(os.Stderr, "Error promoting memory: %v\n", err)
-					}
-					os.Exit(1)
+				strategy = string(chosen)
+			}
+
+			strat := store.ResolutionStrategy(strategy)
+			if !strat.IsValid() {
+				if resolveRobot {
+					fmt.Printf(`{"status":"error","message":"invalid strategy %s"}`+"\n", strategy)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: invalid strategy '%s'. Must be one of: keep-first, keep-second, merge, llm-merge, keep-both\n", strategy)
 				}
+				os.Exit(1)
+			}
 
-				if robotMode {
-					fmt.Printf(`{"status":"ok","message":"promoted memory %s to global store"}`+"\n", id)
+			var ollama *db.OllamaClient
+			if strat == store.StrategyLLMMerge {
+				ollama = db.NewOllamaClientFromEnv()
+			}
+
+			res, err := store.ResolveConflict(context.Background(), id1, id2, strat, ollama)
+			if err != nil {
+				if resolveRobot {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
 				} else {
-					fmt.Printf("✓ Promoted memory %s to global brain (%s)\n", id, globalPath)
+					fmt.Fprintf(os.Stderr, "Error resolving conflict: %v\n", err)
+				}
+				os.Exit(1)
+			}
+
+			if resolveRobot {
+				result := map[string]interface{}{
+					"status":     "ok",
+					"resolution": res,
 				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
+
+			switch strat {
+			case store.StrategyKeepBoth:
+				fmt.Println("✓ Kept both memories; conflict dismissed.")
+			case store.StrategyMerge, store.StrategyLLMMerge:
+				fmt.Printf("✓ Merged into %s, deprecated %s\n", res.WinnerID, res.LoserID)
+				fmt.Printf("  Content: %s\n", res.Content)
+			default:
+				fmt.Printf("✓ Kept %s, deprecated %s\n", res.WinnerID, res.LoserID)
 			}
 		},
 	}
-	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
-	cmd.Flags().StringVar(&globalPath, "path", "/home/hargabyte/.ami/global", "Path to the global AMI store")
-	cmd.Flags().BoolVar(&autoPromote, "auto", false, "Auto-promote memories meeting criteria")
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show candidates without promoting")
-	return cmd
-}
-
-func helpAgentsCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "help-agents",
-		Short: "Output agent-optimized command reference",
+	resolveCmd.Flags().StringVar(&strategy, "strategy", "", "Resolution strategy (keep-first|keep-second|merge|llm-merge|keep-both)")
+	resolveCmd.Flags().BoolVar(&resolveRobot, "robot", false, "Robot mode: output JSON")
+
+	var detectTeam string
+	var threshold float64
+	var lexicalThreshold float64
+	var scanLimit int
+	var useLLM bool
+	var detectRobot bool
+
+	detectCmd := &cobra.Command{
+		Use:   "detect",
+		Short: "Find candidate contradictions among similar memories",
+		Long: `Compare verified memories within each team and flag highly similar pairs.
+Embeddings are compared by cosine similarity; memories without embeddings fall
+back to term overlap. With --llm, the local Ollama model is asked whether each
+candidate pair actually contradicts, and only those pairs are recorded.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println(`# CHAOS Command Reference for AI Agents
+			repoPath, err := os.Getwd()
+			if err != nil {
+				if detectRobot {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				}
+				os.Exit(1)
+			}
+			db.InitDB(repoPath)
+			defer db.CloseDB()
 
-> Cognitive Heuristic Agent Operating System
-> This system manages your long-term memory using a versioned, metabolic architecture.
-> Use it to store facts, decisions, and patterns so you don't burn tokens re-learning them.
+			opts := store.ConflictDetectOptions{
+				TeamID:           detectTeam,
+				Threshold:        threshold,
+				LexicalThreshold: lexicalThreshold,
+				Limit:            scanLimit,
+			}
+			if useLLM {
+				opts.Ollama = db.NewOllamaClientFromEnv()
+			}
 
-## 🧠 Quick Start Workflow
+			conflicts, failures, err := store.DetectConflicts(context.Background(), opts)
+			if err != nil {
+				if detectRobot {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error detecting conflicts: %v\n", err)
+				}
+				os.Exit(1)
+			}
 
-1. **Before a Task**: Get focused context
-   ` + "`" + `chaos context "your task description" --limit 5 --robot` + "`" + `
+			printConflicts(conflicts, failures, detectRobot, "New conflicts")
+		},
+	}
+	detectCmd.Flags().StringVar(&detectTeam, "team", "", "Only compare memories from this team")
+	detectCmd.Flags().Float64Var(&threshold, "threshold", 0.85, "Minimum embedding similarity for a candidate pair")
+	detectCmd.Flags().Float64Var(&lexicalThreshold, "lexical-threshold", 0.5, "Minimum term overlap when embeddings are missing")
+	detectCmd.Flags().IntVar(&scanLimit, "limit", 500, "Maximum number of memories to scan")
+	detectCmd.Flags().BoolVar(&useLLM, "llm", false, "Ask the local Ollama model to confirm each contradiction")
+	detectCmd.Flags().BoolVar(&detectRobot, "robot", false, "Robot mode: output JSON")
 
-2. **During a Task**: Store important discoveries
-   ` + "`" + `chaos add "Decision: use Go 1.22 for this module" --category working --tags technical` + "`" + `
+	var listStatus string
+	var listTeam string
+	var listRobot bool
 
-3. **During a Task**: Track decisions with linked memories
-   ` + "`" + `chaos decision track "Use binary embeddings" --task "v0.4.0" --memories "abc,def"` + "`" + `
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recorded conflicts",
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
 
-4. **After a Task**: Record decision outcomes
-   ` + "`" + `chaos decision outcome <id> --outcome 0.9 --feedback "Worked perfectly"` + "`" + `
+			status := store.ConflictStatus(listStatus)
+			if listStatus == "all" {
+				status = ""
+			}
 
-5. **After a Task**: Clean up and promote
-   ` + "`" + `chaos promote <memory-id>` + "`" + ` (if it's a permanent team truth)
+			conflicts, err := store.ListConflicts(status, listTeam)
+			if err != nil {
+				if listRobot {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error listing conflicts: %v\n", err)
+				}
+				os.Exit(1)
+			}
 
-6. **Periodic Maintenance**: Reflect on episodic noise
-   ` + "`" + `chaos reflect --limit 10 --hours 24` + "`" + `
+			printConflicts(conflicts, nil, listRobot, "Conflicts")
+		},
+	}
+	listCmd.Flags().StringVar(&listStatus, "status", "open", "Filter by status (open|resolved|dismissed|all)")
+	listCmd.Flags().StringVar(&listTeam, "team", "", "Filter by team")
+	listCmd.Flags().BoolVar(&listRobot, "robot", false, "Robot mode: output JSON")
 
-## 📂 Memory Categories
+	cmd.AddCommand(detectCmd)
+	cmd.AddCommand(listCmd)
+	cmd.AddCommand(resolveCmd)
+	return cmd
+}
 
-- **Core**: Foundational truths (User name, identity). Use for facts that NEVER change.
-- **Semantic**: Learned patterns/habits. Use for general knowledge gained over time.
-- **Working**: Task-specific context. Use for notes on the current session.
-- **Episodic**: Event logs. Use for "I did X at time Y".
+// printConflicts renders conflicts with the resolve command for each pair,
+// followed by any pairs the LLM failed to check
+func printConflicts(conflicts []store.Conflict, failures []store.ConflictCheckFailure, robotMode bool, heading string) {
+	if robotMode {
+		entries := make([]map[string]interface{}, 0, len(conflicts))
+		for _, c := range conflicts {
+			entries = append(entries, map[string]interface{}{
+				"conflict":        c,
+				"resolve_command": c.ResolveCommand(),
+			})
+		}
+		result := map[string]interface{}{
+			"status":    "ok",
+			"count":     len(conflicts),
+			"conflicts": entries,
+		}
+		if len(failures) > 0 {
+			result["failures"] = failures
+		}
+		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(jsonBytes))
+		return
+	}
 
-## 🎯 Decision Tracking (v0.5.0+)
+	if len(failures) > 0 {
+		defer func() {
+			fmt.Fprintf(os.Stderr, "\nWarning: could not check %d pair(s); they will be retried on the next run:\n", len(failures))
+			for _, f := range failures {
+				fmt.Fprintf(os.Stderr, "  - %s <-> %s: %s\n", f.MemoryA, f.MemoryB, f.Error)
+			}
+		}()
+	}
 
-Track the decisions you make and learn from their outcomes:
+	fmt.Printf("%s (%d):\n", heading, len(conflicts))
+	if len(conflicts) == 0 {
+		fmt.Println("No conflicts found.")
+		return
+	}
+
+	for i, c := range conflicts {
+		fmt.Printf("\n%d. %s <-> %s [%s] (%s similarity %.2f, team: %s)\n", i+1, c.MemoryA, c.MemoryB, c.Status, c.Method, c.Similarity, c.TeamID)
+		if c.Reason != "" {
+			fmt.Printf("   Reason: %s\n", c.Reason)
+		}
+		if c.Status == store.ConflictOpen {
+			fmt.Printf("   → %s\n", c.ResolveCommand())
+		}
+	}
+}
 
-**Track a Decision:**
-   ` + "`" + `ami decision track "your decision text" --task "project-id" --memories "id1,id2"` + "`" + `
+func reviewCmd() *cobra.Command {
+	var source string
+	var teamID string
+	var ownerID string
+	var limit int
+	var reviewer string
+	var all bool
+	var robotMode bool
 
-**Record Outcome:**
-   ` + "`" + `ami decision outcome <decision-id> --outcome 0.8 --feedback "Notes"` + "`" + `
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Review memories staged as under_review",
+		Long: `Work through the review queue of auto-extracted memories.
 
-**Synaptic Boost:** When outcome > 0.8, linked memories automatically get priority reinforcement.
+Actions:
+  list                    - List staged memories
+  approve [id...]         - Mark memories as verified
+  reject [id...]          - Deprecate memories
+  edit <id> <content>     - Rewrite a staged memory (add --approve to verify it)
 
-## 🔍 Reflection (v0.5.0+)
+approve and reject accept several IDs, or --all to act on every staged memory
+matching --source, --team and --owner.
 
-   ` + "`" + `ami reflect --limit 10 --hours 24` + "`" + `
+Examples:
+  ami review list --source mattermost
+  ami review approve abc-123 def-456
+  ami review reject --all --team AMI-Dev --source mattermost`,
+	}
 
-Identifies episodic noise and suggests semantic synthesis for consolidation.
+	// resolveIDs returns explicit IDs, or the filtered queue when --all is set
+	resolveIDs := func(args []string) ([]string, error) {
+		if !all {
+			return args, nil
+		}
+		if len(args) > 0 {
+			return nil, fmt.Errorf("pass either memory IDs or --all, not both")
+		}
+		queue, err := store.ListReviewQueue(store.ReviewFilter{Source: source, TeamID: teamID, OwnerID: ownerID})
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(queue))
+		for _, m := range queue {
+			ids = append(ids, m.ID)
+		}
+		return ids, nil
+	}
 
-## 🤖 Robot Mode
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List memories awaiting review",
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
 
-ALWAYS use the ` + "`" + `--robot` + "`" + ` flag for programmatic integration.
-It returns pure JSON to stdout.
+			memories, err := store.ListReviewQueue(store.ReviewFilter{
+				Source:  source,
+				TeamID:  teamID,
+				OwnerID: ownerID,
+				Limit:   limit,
+			})
+			if err != nil {
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error listing review queue: %v\n", err)
+				}
+				os.Exit(1)
+			}
 
-## 💡 Best Practices
+			if robotMode {
+				result := map[string]interface{}{
+					"status":   "ok",
+					"count":    len(memories),
+					"memories": memories,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+				return
+			}
 
-- **Atomic Memories**: One fact per memory. Don't mix user preferences with technical specs.
-- **Source Attribution**: Always use ` + "`" + `--source` + "`" + ` so future you knows WHY you believe a fact.
-- **Aggressive Tagging**: Use tags for project IDs and concepts to make filtering faster.
-- **Decision Tracking**: Link memories to decisions so successful choices reinforce useful knowledge.
-- **Regular Reflection**: Use ` + "`" + `ami reflect` + "`" + ` to convert episodic noise into semantic facts.
-`)
+			fmt.Printf("Review queue (%d):\n\n", len(memories))
+			if len(memories) == 0 {
+				fmt.Println("Nothing to review.")
+				return
+			}
+			for i, m := range memories {
+				fmt.Printf("%d. [%s] %s (%s)\n", i+1, m.Category, m.ID, m.CreatedAt.Format("2006-01-02 15:04"))
+				fmt.Printf("   %s\n", m.Content)
+				fmt.Printf("   Source: %s | Team: %s | Owner: %s\n\n", m.Source, m.TeamID, m.OwnerID)
+			}
 		},
 	}
-}
+	listCmd.Flags().IntVar(&limit, "limit", 50, "Maximum number of results")
 
-func deleteCmd() *cobra.Command {
-	var robotMode bool
+	decide := func(verb, past string, apply func([]string, string) (int, error)) func(cmd *cobra.Command, args []string) {
+		return func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
 
-	cmd := &cobra.Command{
-		Use:   "delete [id]",
-		Short: "Delete a memory by ID",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			// Initialize database
-			repoPath, err := os.Getwd()
+			ids, err := resolveIDs(args)
+			if err == nil && len(ids) == 0 && !all {
+				err = fmt.Errorf("memory ID required (or use --all)")
+			}
 			if err != nil {
 				if robotMode {
 					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
 				} else {
-					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				}
 				os.Exit(1)
 			}
 
-			if err := db.InitDB(repoPath); err != nil {
+			if all && !robotMode && len(ids) > 0 && !confirmAction(fmt.Sprintf("%s %d staged memories?", verb, len(ids))) {
+				fmt.Println("Review cancelled.")
+				return
+			}
+
+			count, err := apply(ids, reviewer)
+			if err != nil {
 				if robotMode {
 					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
 				} else {
-					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				}
 				os.Exit(1)
 			}
+
+			if robotMode {
+				result := map[string]interface{}{
+					"status":    "ok",
+					"action":    strings.ToLower(verb),
+					"requested": len(ids),
+					"count":     count,
+				}
+				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+				fmt.Println(string(jsonBytes))
+			} else {
+				fmt.Printf("✓ %s %d of %d memory(ies)\n", past, count, len(ids))
+			}
+		}
+	}
+
+	approveCmd := &cobra.Command{
+		Use:   "approve [id...]",
+		Short: "Approve staged memories",
+		Run:   decide("Approve", "Approved", store.ApproveMemories),
+	}
+	rejectCmd := &cobra.Command{
+		Use:   "reject [id...]",
+		Short: "Reject staged memories",
+		Run:   decide("Reject", "Rejected", store.RejectMemories),
+	}
+	for _, c := range []*cobra.Command{approveCmd, rejectCmd} {
+		c.Flags().BoolVar(&all, "all", false, "Act on every staged memory matching the filters")
+	}
+
+	var approveEdit bool
+	editCmd := &cobra.Command{
+		Use:   "edit <id> <content>",
+		Short: "Rewrite a staged memory",
+		Args:  cobra.MinimumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
 			defer db.CloseDB()
 
 			id := args[0]
-
-			// Delete the memory
-			if err := store.DeleteMemory(id); err != nil {
+			content := strings.Join(args[1:], " ")
+			if err := store.EditReviewMemory(id, content, reviewer, approveEdit); err != nil {
 				if robotMode {
 					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
 				} else {
-					fmt.Fprintf(os.Stderr, "Error deleting memory: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Error editing memory: %v\n", err)
 				}
 				os.Exit(1)
 			}
 
 			if robotMode {
-				fmt.Printf(`{"status":"ok","message":"deleted memory %s"}`+"\n", id)
+				fmt.Printf(`{"status":"ok","message":"edited memory %s","approved":%t}`+"\n", id, approveEdit)
+			} else if approveEdit {
+				fmt.Printf("✓ Edited and approved memory %s\n", id)
 			} else {
-				fmt.Printf("✓ Deleted memory %s\n", id)
+				fmt.Printf("✓ Edited memory %s (still under review)\n", id)
 			}
 		},
 	}
-	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	editCmd.Flags().BoolVar(&approveEdit, "approve", false, "Approve the memory after editing")
+
+	cmd.PersistentFlags().StringVar(&source, "source", "", "Filter by source (e.g. mattermost, reflect, consolidate)")
+	cmd.PersistentFlags().StringVar(&teamID, "team", "", "Filter by team")
+	cmd.PersistentFlags().StringVar(&ownerID, "owner", "", "Filter by owner")
+	cmd.PersistentFlags().StringVar(&reviewer, "reviewer", store.DefaultReviewer(), "Reviewer identity to record")
+	cmd.PersistentFlags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+
+	cmd.AddCommand(listCmd)
+	cmd.AddCommand(approveCmd)
+	cmd.AddCommand(rejectCmd)
+	cmd.AddCommand(editCmd)
 	return cmd
 }
 
-func tagsCmd() *cobra.Command {
+func expireCmd() *cobra.Command {
+	var dryRun bool
 	var robotMode bool
 
 	cmd := &cobra.Command{
-		Use:   "tags",
-		Short: "List all unique tags",
+		Use:   "expire",
+		Short: "Archive memories whose TTL has passed",
+		Long: `Archive every memory whose expires_at is in the past, in a single Dolt commit.
+
+Expired memories are already hidden from recall, catchup, keystones,
+context and stats unless --status archived is asked for; expire moves them
+to the archived status so they can be reviewed there.
+
+Examples:
+  ami expire --dry-run
+  ami expire`,
 		Run: func(cmd *cobra.Command, args []string) {
-			// Initialize database
 			repoPath, err := os.Getwd()
 			if err != nil {
 				if robotMode {
@@ -1108,496 +4281,468 @@ func tagsCmd() *cobra.Command {
 			}
 			defer db.CloseDB()
 
-			tags, err := store.ListTags()
+			expired, err := store.ExpireMemories(dryRun)
 			if err != nil {
 				if robotMode {
 					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
 				} else {
-					fmt.Fprintf(os.Stderr, "Error listing tags: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Error expiring memories: %v\n", err)
 				}
 				os.Exit(1)
 			}
 
 			if robotMode {
 				result := map[string]interface{}{
-					"status": "ok",
-					"tags":   tags,
+					"status":   "ok",
+					"dry_run":  dryRun,
+					"count":    len(expired),
+					"memories": expired,
 				}
 				jsonBytes, _ := json.MarshalIndent(result, "", "  ")
 				fmt.Println(string(jsonBytes))
-			} else {
-				fmt.Println("Unique Tags:")
-				for _, tag := range tags {
-					fmt.Printf("- %s\n", tag)
-				}
+				return
 			}
-		},
-	}
-	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
-	return cmd
-}
 
-func checkpointCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "checkpoint [description]",
-		Short: "Create a checkpoint before compression",
-		Run: func(cmd *cobra.Command, args []string) {
-			// TODO: Implement
-			fmt.Println("Checkpointing current state")
-		},
-	}
-}
+			if len(expired) == 0 {
+				fmt.Println("No expired memories.")
+				return
+			}
 
-func consolidateCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "consolidate",
-		Short: "Consolidate episodic memories to semantic",
-		Run: func(cmd *cobra.Command, args []string) {
-			// TODO: Implement
-			fmt.Println("Consolidating memories")
+			verb := "Archived"
+			if dryRun {
+				verb = "Would archive"
+			}
+			fmt.Printf("%s %d expired memory(ies):\n", verb, len(expired))
+			for _, m := range expired {
+				fmt.Printf("  - [%s] %s (expired %s)\n", m.ID[:8], m.Content, m.ExpiresAt.Format("2006-01-02 15:04"))
+			}
 		},
 	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List expired memories without archiving them")
+	cmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
+	return cmd
 }
 
-func decisionCmd() *cobra.Command {
+func pairingCmd() *cobra.Command {
 	var taskID string
-	var memoryIDsStr string
-	var outcomeStr string
-	var feedback string
+	var useBranch bool
+	var teamID string
 	var robotMode bool
 
 	cmd := &cobra.Command{
-		Use:   "decision [action]",
-		Short: "Track decisions and their outcomes",
-		Long: `Track decisions and reinforce memories that lead to good outcomes.
+		Use:   "pairing",
+		Short: "Manage session pairing daemon",
+		Long: `The pairing daemon records every action reported to it into a session log
+for the task. With --branch, each action is also committed to a draft
+pairing/<task> Dolt branch, leaving your current branch untouched.
 
-Actions:
-  track [decision]    - Track a new decision with linked memories
-  outcome <id>        - Record the outcome of a decision (0.0 to 1.0)
-  list [task_id]      - List all decisions, optionally filtered by task
+When the task is done, "ami pairing commit" synthesizes the session's
+discoveries into memories staged for review and merges them with the
+recorded actions; "ami pairing discard" throws the session away.
 
-Examples:
-  ami decision track "Use binary embeddings" --task "v0.4.0" --memories "abc,def"
-  ami decision outcome abc-123 --outcome 0.9 --feedback "Worked perfectly"
-  ami decision list v0.4.0`,
+The daemon listens on a per-user socket in $XDG_RUNTIME_DIR/ami (or a
+private directory under the system temp dir), next to its PID file and,
+when started with --detach, its log.`,
 	}
+	cmd.PersistentFlags().StringVar(&taskID, "task", "default", "Task ID to associate with the session")
+	cmd.PersistentFlags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
 
-	trackCmd := &cobra.Command{
-		Use:   "track [decision]",
-		Short: "Track a new decision",
-		Args:  cobra.MinimumNArgs(1),
+	var detach bool
+	startCmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the pairing daemon",
 		Run: func(cmd *cobra.Command, args []string) {
-			// Initialize database
-			repoPath, err := os.Getwd()
-			if err != nil {
-				if robotMode {
-					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
-				} else {
-					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+			if detach {
+				pid, err := detachPairingDaemon(taskID, useBranch)
+				if err != nil {
+					if robotMode {
+						fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+					} else {
+						fmt.Fprintf(os.Stderr, "Error starting pairing daemon: %v\n", err)
+					}
+					os.Exit(1)
 				}
-				os.Exit(1)
-			}
-
-			if err := db.InitDB(repoPath); err != nil {
 				if robotMode {
-					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
-				} else {
-					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+					output, _ := json.Marshal(map[string]interface{}{
+						"status":  "success",
+						"pid":     pid,
+						"task_id": taskID,
+						"log":     store.PairingLogPath(),
+					})
+					fmt.Println(string(output))
+					return
 				}
-				os.Exit(1)
+				fmt.Printf("✓ Pairing daemon started in the background (pid %d, Task: %s)\n", pid, taskID)
+				fmt.Printf("Log: %s\n", store.PairingLogPath())
+				return
 			}
+
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
 			defer db.CloseDB()
 
-			// Parse memory IDs
-			var memoryIDs []string
-			if memoryIDsStr != "" {
-				memoryIDs = strings.Split(memoryIDsStr, ",")
-				// Trim spaces from each ID
-				for i, id := range memoryIDs {
-					memoryIDs[i] = strings.TrimSpace(id)
-				}
+			daemon, err := store.ListenPairing()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error starting pairing daemon: %v\n", err)
+				os.Exit(1)
 			}
 
-			// Track the decision
-			decisionText := strings.Join(args, " ")
-			decision, err := store.TrackDecision(taskID, memoryIDs, decisionText, "cli")
+			session, err := store.StartPairingSession(taskID, useBranch)
 			if err != nil {
-				if robotMode {
-					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
-				} else {
-					fmt.Fprintf(os.Stderr, "Error tracking decision: %v\n", err)
-				}
+				daemon.Close()
+				fmt.Fprintf(os.Stderr, "Error starting pairing session: %v\n", err)
 				os.Exit(1)
 			}
 
-			if robotMode {
-				jsonBytes, _ := json.MarshalIndent(decision, "", "  ")
-				fmt.Println(string(jsonBytes))
-			} else {
-				fmt.Printf("✓ Decision tracked: %s\n", decision.ID)
-				fmt.Printf("  Task: %s\n", decision.TaskID)
-				fmt.Printf("  Commit: %s\n", decision.CommitHash)
-				fmt.Printf("  Text: %s\n", decision.DecisionText)
-				if len(decision.MemoryIDs) > 0 {
-					fmt.Printf("  Linked memories: %d\n", len(decision.MemoryIDs))
-				}
+			daemon.OnAction = func(a store.SessionAction) {
+				fmt.Printf(" [LOG] %s\n", a.LogLine())
+			}
+			daemon.OnError = func(err error) {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 			}
-		},
-	}
-	trackCmd.Flags().StringVar(&taskID, "task", "", "Task ID")
-	trackCmd.Flags().StringVar(&memoryIDsStr, "memories", "", "Comma-separated memory IDs")
 
-	outcomeCmd := &cobra.Command{
-		Use:   "outcome <decision_id>",
-		Short: "Record the outcome of a decision",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
-			// Initialize database
-			repoPath, err := os.Getwd()
-			if err != nil {
-				if robotMode {
-					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
-				} else {
-					fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
-				}
-				os.Exit(1)
+			sigs := make(chan os.Signal, 1)
+			signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+			go func() {
+				<-sigs
+				daemon.Close()
+			}()
+
+			fmt.Printf("✓ Pairing daemon started at %s (Task: %s, pid %d)\n", daemon.Socket, taskID, os.Getpid())
+			if session.Branch != "" {
+				fmt.Printf("Recording to branch %s\n", session.Branch)
 			}
+			fmt.Println("Listening for tool reports...")
 
-			if err := db.InitDB(repoPath); err != nil {
-				if robotMode {
-					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
-				} else {
-					fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
-				}
+			if err := daemon.Serve(session); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
+			fmt.Println("Pairing daemon stopped")
+		},
+	}
+	startCmd.Flags().BoolVar(&detach, "detach", false, "Run the daemon in the background")
+	startCmd.Flags().BoolVar(&useBranch, "branch", false, "Also record actions on a pairing/<task> Dolt branch")
+
+	commitCmd := &cobra.Command{
+		Use:   "commit",
+		Short: "Synthesize the session's discoveries and merge them",
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
 			defer db.CloseDB()
 
-			decisionID := args[0]
-			var outcome float64
-			_, err = fmt.Sscanf(outcomeStr, "%f", &outcome)
+			result, err := store.CommitPairingSession(context.Background(), db.NewOllamaClientFromEnv(), taskID, teamID)
 			if err != nil {
 				if robotMode {
-					fmt.Printf(`{"status":"error","message":"invalid outcome: %v"}`+"\n", err)
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
 				} else {
-					fmt.Fprintf(os.Stderr, "Error: invalid outcome value\n")
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				}
 				os.Exit(1)
 			}
 
-			// Validate outcome range
-			if outcome < 0.0 || outcome > 1.0 {
-				if robotMode {
-					fmt.Printf(`{"status":"error","message":"outcome must be between 0.0 and 1.0"}`+"\n")
-				} else {
-					fmt.Fprintf(os.Stderr, "Error: outcome must be between 0.0 and 1.0\n")
-				}
-				os.Exit(1)
+			if robotMode {
+				output, _ := json.MarshalIndent(map[string]interface{}{
+					"status": "success",
+					"result": result,
+				}, "", "  ")
+				fmt.Println(string(output))
+				return
 			}
 
-			// Record the outcome
-			err = store.RecordOutcome(decisionID, outcome, feedback)
-			if err != nil {
+			if result.Actions == 0 {
+				fmt.Printf("Session %s recorded no actions; nothing to commit\n", taskID)
+				return
+			}
+			fmt.Printf("✓ Committed pairing session %s (%d action(s))\n", taskID, result.Actions)
+			if result.Branch != "" {
+				fmt.Printf("Merged and deleted branch %s\n", result.Branch)
+			}
+			fmt.Printf("Staged %d of %d fact(s) for review (the rest are already known):\n", len(result.Staged), len(result.Facts))
+			for _, f := range result.Facts {
+				fmt.Printf("- %s\n", f)
+			}
+			if len(result.Staged) > 0 {
+				fmt.Println("\nReview with: ami review list --source pairing")
+			}
+		},
+	}
+	commitCmd.Flags().StringVar(&teamID, "team", "system", "Team ID for the synthesized memories")
+
+	discardCmd := &cobra.Command{
+		Use:   "discard",
+		Short: "Drop the session's log and draft branch",
+		Run: func(cmd *cobra.Command, args []string) {
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			if err := store.DiscardPairingSession(taskID); err != nil {
 				if robotMode {
 					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
 				} else {
-					fmt.Fprintf(os.Stderr, "Error recording outcome: %v\n", err)
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				}
 				os.Exit(1)
 			}
 
 			if robotMode {
-				fmt.Printf(`{"status":"success","decision_id":"%s","outcome":%f}`+"\n", decisionID, outcome)
-			} else {
-				fmt.Printf("✓ Outcome recorded: %.2f\n", outcome)
-				if outcome > 0.8 {
-					fmt.Printf("  → High success! Linked memories reinforced.\n")
-				}
+				fmt.Printf(`{"status":"success","task_id":"%s"}`+"\n", taskID)
+				return
 			}
+			fmt.Printf("✓ Discarded pairing session %s\n", taskID)
 		},
 	}
-	outcomeCmd.Flags().StringVar(&outcomeStr, "outcome", "", "Outcome value (0.0 to 1.0)")
-	outcomeCmd.Flags().StringVar(&feedback, "feedback", "", "Optional feedback text")
-	outcomeCmd.MarkFlagRequired("outcome")
 
-	listCmd := &cobra.Command{
-		Use:   "list [task_id]",
-		Short: "List decisions",
-		Args:  cobra.MaximumNArgs(1),
+	hookCmd := &cobra.Command{
+		Use:   "hook <bash|zsh|fish>",
+		Short: "Print a shell hook that reports every command to the daemon",
+		Long: `Print a shell snippet that reports each command line to the pairing daemon
+with its working directory, exit code, duration and git branch.
+
+Reports are sent in the background and skipped entirely while the daemon
+is not running, so the hook never slows down your prompt.
+
+  bash:  eval "$(ami pairing hook bash)"     # in ~/.bashrc
+  zsh:   eval "$(ami pairing hook zsh)"      # in ~/.zshrc
+  fish:  ami pairing hook fish | source      # in ~/.config/fish/config.fish`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: store.PairingHookShells,
 		Run: func(cmd *cobra.Command, args []string) {
-			// Initialize database
-			repoPath, err := os.Getwd()
+			exe, err := os.Executable()
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
-				os.Exit(1)
+				exe = "ami"
 			}
-
-			if err := db.InitDB(repoPath); err != nil {
-				fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
+			hook, err := store.PairingHook(args[0], exe)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
-			defer db.CloseDB()
+			fmt.Print(hook)
+		},
+	}
 
-			listTaskID := ""
-			if len(args) > 0 {
-				listTaskID = args[0]
+	var exitCode int
+	var durationMs int64
+	var cwd, gitBranch, shell string
+	reportCmd := &cobra.Command{
+		Use:   "report [flags] -- <command line>",
+		Short: "Report one command to the pairing daemon (used by shell hooks)",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			action := store.PairingAction{
+				Version:    store.PairingWireVersion,
+				Action:     strings.Join(args, " "),
+				Source:     "shell",
+				Cwd:        cwd,
+				DurationMs: durationMs,
+				GitBranch:  gitBranch,
+				Shell:      shell,
 			}
-
-			decisions, err := store.ListDecisions(listTaskID)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error listing decisions: %v\n", err)
-				os.Exit(1)
+			// The daemon files actions under its own task unless told otherwise
+			if cmd.Flags().Changed("task") {
+				action.TaskID = taskID
 			}
-
-			if robotMode {
-				jsonBytes, _ := json.MarshalIndent(decisions, "", "  ")
-				fmt.Println(string(jsonBytes))
-			} else {
-				if len(decisions) == 0 {
-					fmt.Println("No decisions found.")
-					return
-				}
-
-				for _, d := range decisions {
-					outcomeStr := "pending"
-					if d.Outcome > 0 {
-						outcomeStr = fmt.Sprintf("%.2f", d.Outcome)
-					}
-
-					fmt.Printf("\n%s\n", d.ID)
-					fmt.Printf("  Task: %s\n", d.TaskID)
-					fmt.Printf("  Commit: %s\n", d.CommitHash)
-					fmt.Printf("  Decision: %s\n", d.DecisionText)
-					fmt.Printf("  Outcome: %s\n", outcomeStr)
-					if d.Feedback != "" {
-						fmt.Printf("  Feedback: %s\n", d.Feedback)
-					}
-					if len(d.MemoryIDs) > 0 {
-						fmt.Printf("  Linked memories: %d\n", len(d.MemoryIDs))
-					}
+			if cmd.Flags().Changed("exit-code") {
+				action.ExitCode = &exitCode
+			}
+			if action.Cwd == "" {
+				action.Cwd, _ = os.Getwd()
+			}
+			if action.GitBranch == "" {
+				// symbolic-ref also names unborn branches; detached heads fall back to a short SHA
+				branch, err := db.RunGit(action.Cwd, "symbolic-ref", "--short", "-q", "HEAD")
+				if err != nil {
+					branch, _ = db.RunGit(action.Cwd, "rev-parse", "--short", "HEAD")
 				}
+				action.GitBranch = branch
 			}
+			// Reporting is best effort; a missing daemon is not an error
+			store.ReportToPairing(action)
 		},
 	}
-	listCmd.Flags().BoolVar(&robotMode, "robot", false, "Robot mode: output JSON")
-
-	cmd.AddCommand(trackCmd)
-	cmd.AddCommand(outcomeCmd)
-	cmd.AddCommand(listCmd)
-
-	return cmd
-}
-
-func reflectCmd() *cobra.Command {
-	var hours int
-	var limit int
-
-	cmd := &cobra.Command{
-		Use:   "reflect",
-		Short: "Reflect on episodic memories and suggest synthesis",
-		Long: `Reflect on recent episodic memories and suggest semantic synthesis.
-This is the MVP for autonomous reflection - it identifies noisy clusters
-and provides synthesis prompts for consolidation.`,
+	reportCmd.Flags().IntVar(&exitCode, "exit-code", 0, "Exit code of the command")
+	reportCmd.Flags().Int64Var(&durationMs, "duration-ms", 0, "How long the command ran, in milliseconds")
+	reportCmd.Flags().StringVar(&cwd, "cwd", "", "Directory the command ran in (default: current directory)")
+	reportCmd.Flags().StringVar(&gitBranch, "git-branch", "", "Git branch (default: detected from --cwd)")
+	reportCmd.Flags().StringVar(&shell, "shell", "", "Shell that ran the command")
+
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the pairing daemon",
 		Run: func(cmd *cobra.Command, args []string) {
-			// Initialize database
-			repoPath, err := os.Getwd()
+			pid, err := store.StopPairingDaemon()
+			if errors.Is(err, store.ErrPairingNotRunning) {
+				if robotMode {
+					fmt.Println(`{"status":"success","running":false}`)
+				} else {
+					fmt.Println("Pairing daemon is not running")
+				}
+				return
+			}
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error getting working directory: %v\n", err)
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
 				os.Exit(1)
 			}
 
-			if err := db.InitDB(repoPath); err != nil {
-				fmt.Fprintf(os.Stderr, "Error initializing database: %v\n", err)
-				os.Exit(1)
+			if robotMode {
+				fmt.Printf(`{"status":"success","pid":%d}`+"\n", pid)
+				return
 			}
-			defer db.CloseDB()
-
-			// Calculate the time threshold
-			sinceTime := time.Now().Add(-time.Duration(hours) * time.Hour)
-
-			// Get episodic memories from the last N hours
-			memories, err := store.CatchupMemories(store.CatchupOptions{
-				Limit:    limit,
-				Category: "episodic",
-				Since:    sinceTime.Format("2006-01-02 15:04:05"),
-			})
+			fmt.Printf("✓ Stopped pairing daemon (pid %d)\n", pid)
+		},
+	}
 
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the pairing daemon is running, and its session stats",
+		Run: func(cmd *cobra.Command, args []string) {
+			resp, err := store.QueryPairing(store.PairingRequest{Op: store.PairingOpStatus})
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error fetching memories: %v\n", err)
+				if robotMode {
+					output, _ := json.MarshalIndent(map[string]interface{}{
+						"status":  "stopped",
+						"message": err.Error(),
+						"socket":  store.GetSocketPath(),
+					}, "", "  ")
+					fmt.Println(string(output))
+				} else if errors.Is(err, store.ErrPairingNotRunning) {
+					fmt.Println("Pairing daemon is not running")
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
 				os.Exit(1)
 			}
 
-			if len(memories) == 0 {
-				fmt.Println("No episodic memories found for reflection.")
+			st := resp.Status
+			if robotMode {
+				output, _ := json.MarshalIndent(map[string]interface{}{
+					"status": "running",
+					"daemon": st,
+				}, "", "  ")
+				fmt.Println(string(output))
 				return
 			}
 
-			fmt.Printf("🤔 Reflecting on %d episodic memories from the last %d hour(s)\n\n", len(memories), hours)
-
-			// Display memories
-			for i, m := range memories {
-				fmt.Printf("%d. [%s] %s\n", i+1, m.ID[:8], m.Content)
-				if len(m.Tags) > 0 {
-					fmt.Printf("   Tags: %v\n", m.Tags)
-				}
-			}
-
-			fmt.Println("\n--- Synthesis Prompt ---")
-			fmt.Println("Review the above memories and suggest 1-3 Semantic Facts that:")
-			fmt.Println("  • Capture the essential knowledge")
-			fmt.Println("  • Eliminate redundant detail")
-			fmt.Println("  • Maintain high information density")
-			fmt.Println()
-			fmt.Println("For each suggested fact, provide:")
-			fmt.Println("  1. Title (short, descriptive)")
-			fmt.Println("  2. Content (concise, definitive statement)")
-			fmt.Println("  3. Related memory IDs (for traceability)")
-			fmt.Println()
-			fmt.Println("Example:")
-			fmt.Println("  Fact 1:")
-			fmt.Println("    Title: Binary embedding storage")
-			fmt.Println("    Content: AMI v0.4.0 uses BLOB fields with HEX encoding to store float32 embeddings,")
-			fmt.Println("               providing bit-perfect precision and enabling semantic search.")
-			fmt.Println("    Related: abc-123, def-456")
+			fmt.Printf("Pairing daemon running (pid %d)\n", st.PID)
+			task := st.TaskID
+			if st.Branch != "" {
+				task += fmt.Sprintf(" (branch %s)", st.Branch)
+			}
+			fmt.Printf("  Task:    %s\n", task)
+			fmt.Printf("  Socket:  %s\n", st.Socket)
+			fmt.Printf("  Uptime:  %s\n", time.Since(st.StartedAt).Round(time.Second))
+			fmt.Printf("  Actions: %d (%d command(s), %d failed)\n", st.Stats.Actions, st.Stats.Commands, st.Stats.Failures)
+			if st.Stats.LastAction != nil {
+				fmt.Printf("  Last:    %s ago\n", time.Since(*st.Stats.LastAction).Round(time.Second))
+			}
+			if st.Stats.Dropped > 0 {
+				fmt.Printf("  Dropped: %d report(s) in an unsupported wire version\n", st.Stats.Dropped)
+			}
 		},
 	}
-	cmd.Flags().IntVar(&hours, "hours", 24, "Hours to look back")
-	cmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of memories to reflect on")
-
-	return cmd
-}
-
-func conflictCmd() *cobra.Command {
-	cmd := &cobra.Command{
-		Use:   "conflict",
-		Short: "Detect and resolve conflicting memories",
-		Long: `Detect memories that may contradict each other using semantic similarity.
-This helps maintain team consensus across multiple agents.`,
-	}
 
-	resolveCmd := &cobra.Command{
-		Use:   "resolve <id1> <id2>",
-		Short: "Resolve a conflict between two memories",
-		Args:  cobra.ExactArgs(2),
+	var recallLimit int
+	recallCmd := &cobra.Command{
+		Use:   "recall <query>",
+		Short: "Recall memories through the running pairing daemon",
+		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			repoPath, _ := os.Getwd()
-			db.InitDB(repoPath)
-			defer db.CloseDB()
-
-			id1 := args[0]
-			id2 := args[1]
-
-			// Get both memories
-			m1, err := store.GetMemoryByID(id1)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error fetching memory %s: %v\n", id1, err)
-				os.Exit(1)
-			}
-			m2, err := store.GetMemoryByID(id2)
+			resp, err := store.QueryPairing(store.PairingRequest{Op: store.PairingOpRecall, Query: args[0], Limit: recallLimit})
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error fetching memory %s: %v\n", id2, err)
+				if robotMode {
+					fmt.Printf(`{"status":"error","message":"%v"}`+"\n", err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
 				os.Exit(1)
 			}
 
-			fmt.Println("Memory 1:")
-			fmt.Printf("  ID: %s\n", m1.ID)
-			fmt.Printf("  Content: %s\n", m1.Content)
-			fmt.Printf("  Category: %s\n", m1.Category)
-
-			fmt.Println("\nMemory 2:")
-			fmt.Printf("  ID: %s\n", m2.ID)
-			fmt.Printf("  Content: %s\n", m2.Content)
-			fmt.Printf("  Category: %s\n", m2.Category)
-
-			fmt.Println("\nResolution options:")
-			fmt.Println("1. Keep Memory 1 (deprecate Memory 2)")
-			fmt.Println("2. Keep Memory 2 (deprecate Memory 1)")
-			fmt.Println("3. Merge into Memory 1")
-			fmt.Println("4. Keep both (no action)")
-
-			fmt.Print("\nSelect option [1-4]: ")
-			var choice int
-			fmt.Scanln(&choice)
+			if robotMode {
+				output, _ := json.MarshalIndent(map[string]interface{}{
+					"status":   "ok",
+					"query":    args[0],
+					"count":    len(resp.Memories),
+					"memories": resp.Memories,
+				}, "", "  ")
+				fmt.Println(string(output))
+				return
+			}
 
-			switch choice {
-			case 1:
-				store.UpdateMemoryStatus(id2, models.StatusDeprecated)
-				fmt.Printf("✓ Deprecated %s\n", id2)
-			case 2:
-				store.UpdateMemoryStatus(id1, models.StatusDeprecated)
-				fmt.Printf("✓ Deprecated %s\n", id1)
-			case 3:
-				// Simple merge: combine content
-				mergedContent := fmt.Sprintf("%s | %s", m1.Content, m2.Content)
-				store.UpdateMemoryContent(id1, mergedContent)
-				store.UpdateMemoryStatus(id2, models.StatusDeprecated)
-				fmt.Printf("✓ Merged into %s, deprecated %s\n", id1, id2)
-			case 4:
-				fmt.Println("No action taken.")
-			default:
-				fmt.Println("Invalid choice.")
+			fmt.Printf("Found %d memory(ies) matching '%s':\n\n", len(resp.Memories), args[0])
+			for i, m := range resp.Memories {
+				fmt.Printf("%d. [%s] %s\n", i+1, m.Category, m.ID)
+				fmt.Printf("   Content: %s\n\n", m.Content)
 			}
 		},
 	}
+	recallCmd.Flags().IntVar(&recallLimit, "limit", 10, "Maximum number of memories to return")
 
-	cmd.AddCommand(resolveCmd)
+	cmd.AddCommand(startCmd, stopCmd, statusCmd, commitCmd, discardCmd, hookCmd, reportCmd, recallCmd)
 	return cmd
 }
 
-func pairingCmd() *cobra.Command {
-	var taskID string
-
-	cmd := &cobra.Command{
-		Use:   "pairing",
-		Short: "Manage session pairing daemon",
+// detachPairingDaemon re-runs "pairing start" in the background with its
+// output appended to the pairing log, and waits until it answers on the
+// socket. It returns the daemon's PID.
+func detachPairingDaemon(taskID string, useBranch bool) (int, error) {
+	if resp, err := store.QueryPairing(store.PairingRequest{Op: store.PairingOpStatus}); err == nil {
+		return 0, fmt.Errorf("pairing daemon already running (pid %d, task %s)", resp.Status.PID, resp.Status.TaskID)
+	}
+	if err := store.EnsurePairingRuntimeDir(); err != nil {
+		return 0, err
 	}
 
-	startCmd := &cobra.Command{
-		Use:   "start",
-		Short: "Start the pairing daemon",
-		Run: func(cmd *cobra.Command, args []string) {
-			socketPath := store.GetSocketPath()
-			
-			// Cleanup old socket
-			os.Remove(socketPath)
-
-			listener, err := net.Listen("unix", socketPath)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error starting pairing daemon: %v\n", err)
-				os.Exit(1)
-			}
-			defer listener.Close()
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	logFile, err := os.OpenFile(store.PairingLogPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer logFile.Close()
 
-			fmt.Printf("✓ Pairing daemon started at %s (Task: %s)\n", socketPath, taskID)
-			fmt.Println("Listening for tool reports...")
+	args := []string{"pairing", "start", "--task", taskID}
+	if useBranch {
+		args = append(args, "--branch")
+	}
+	child := exec.Command(exe, args...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	// Its own session keeps SIGHUP from the terminal away from it
+	child.SysProcAttr = store.DetachedSysProcAttr()
+	if err := child.Start(); err != nil {
+		return 0, err
+	}
 
-			for {
-				conn, err := listener.Accept()
-				if err != nil {
-					continue
-				}
-				
-				go func(c net.Conn) {
-					defer c.Close()
-					var action store.PairingAction
-					if err := json.NewDecoder(c).Decode(&action); err == nil {
-						fmt.Printf(" [LOG] Tool: %s | Action: %s\n", action.Source, action.Action)
-						// In a real impl, we'd write to a draft branch in Dolt here
-					}
-				}(conn)
+	exited := make(chan error, 1)
+	go func() { exited <- child.Wait() }()
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case <-exited:
+			return 0, fmt.Errorf("daemon exited during startup; see %s", store.PairingLogPath())
+		case <-deadline:
+			return child.Process.Pid, fmt.Errorf("daemon (pid %d) did not answer within 5s; see %s", child.Process.Pid, store.PairingLogPath())
+		case <-time.After(100 * time.Millisecond):
+			if resp, err := store.QueryPairing(store.PairingRequest{Op: store.PairingOpStatus}); err == nil {
+				return resp.Status.PID, nil
 			}
-		},
+		}
 	}
-	startCmd.Flags().StringVar(&taskID, "task", "default", "Task ID to associate with the session")
-
-	cmd.AddCommand(startCmd)
-	return cmd
 }
 
 func syncCmd() *cobra.Command {
 	var channelID string
 	var limit int
 	var teamID string
+	var autoApprove bool
 
 	cmd := &cobra.Command{
 		Use:   "sync",
@@ -1627,7 +4772,7 @@ func syncCmd() *cobra.Command {
 			
 			// Process through reflection engine (Ollama)
 			ctx := context.Background()
-			ollama := db.NewOllamaClient("http://localhost:11434", "qwen2.5-coder:1.5b")
+			ollama := db.NewOllamaClientFromEnv()
 			
 			rawContent := strings.Join(messages, "\n---\n")
 			facts, err := store.ExtractTechnicalFacts(ctx, ollama, rawContent)
@@ -1643,16 +4788,47 @@ func syncCmd() *cobra.Command {
 			db.InitDB(repoPath)
 			defer db.CloseDB()
 
+			status := models.StatusUnderReview
+			if autoApprove {
+				status = models.StatusVerified
+			}
+
 			for _, f := range facts {
 				// Add as under_review with team attribution
-				store.AddMemory(f, "system", models.CategorySemantic, 0.5, []string{"mm-sync"}, "mattermost", teamID)
-				fmt.Printf("- %s\n", f)
+				added, err := store.AddMemoryWithParams(store.AddParams{
+					Content:     f,
+					OwnerID:     "system",
+					Category:    models.CategorySemantic,
+					Priority:    0.5,
+					Tags:        []string{"mm-sync"},
+					Source:      "mattermost",
+					TeamID:      teamID,
+					Status:      status,
+					// Re-syncing the same history must not reinforce known
+					// facts or resurrect ones a reviewer deprecated
+					OnDuplicate: store.DuplicateReject,
+					Search:      store.DuplicateSearch{MatchDeprecated: true},
+				})
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error storing fact: %v\n", err)
+					continue
+				}
+				if added.Action == "rejected" {
+					fmt.Printf("- %s (skipped: already known as %s)\n", f, added.Duplicate.Memory.ID)
+					continue
+				}
+				fmt.Printf("- %s (%s)\n", f, added.Action)
+			}
+
+			if !autoApprove {
+				fmt.Println("\nReview with: ami review list --source mattermost")
 			}
 		},
 	}
 	mmCmd.Flags().StringVar(&channelID, "channel", "", "Mattermost Channel ID")
 	mmCmd.Flags().IntVar(&limit, "limit", 20, "Number of messages to pull")
 	mmCmd.Flags().StringVar(&teamID, "team", "system", "Mattermost Team ID for attribution")
+	mmCmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Store facts as verified instead of staging them for review")
 	mmCmd.MarkFlagRequired("channel")
 
 	cmd.AddCommand(mmCmd)
@@ -1695,7 +4871,7 @@ func robotCmd() *cobra.Command {
 			defer db.CloseDB()
 
 			// Get memory count
-			count, err := store.GetMemoryCount()
+			count, err := store.GetMemoryCount(nil)
 			if err != nil {
 				result := map[string]interface{}{
 					"status":  "error",
@@ -1718,10 +4894,37 @@ func robotCmd() *cobra.Command {
 	})
 
 	cmd.AddCommand(&cobra.Command{
-		Use:   "checkpoint",
+		Use:   "checkpoint [description]",
 		Short: "Auto-checkpoint for compression hooks",
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println(`{"checkpointed":true}`)
+			repoPath, _ := os.Getwd()
+			db.InitDB(repoPath)
+			defer db.CloseDB()
+
+			description := strings.Join(args, " ")
+			if description == "" {
+				description = "Auto-checkpoint before context compression"
+			}
+
+			cp, err := store.CreateCheckpoint("", description, "robot")
+			if err != nil {
+				result := map[string]interface{}{
+					"checkpointed": false,
+					"status":       "error",
+					"message":      err.Error(),
+				}
+				jsonBytes, _ := json.Marshal(result)
+				fmt.Println(string(jsonBytes))
+				os.Exit(1)
+			}
+
+			result := map[string]interface{}{
+				"checkpointed": true,
+				"status":       "ok",
+				"checkpoint":   cp,
+			}
+			jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+			fmt.Println(string(jsonBytes))
 		},
 	})
 
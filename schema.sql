@@ -11,8 +11,12 @@ CREATE TABLE IF NOT EXISTS memories (
     source VARCHAR(255),
     tags JSON,
     embedding BLOB,
-    status ENUM('verified', 'under_review', 'deprecated') DEFAULT 'verified',
-    team_id VARCHAR(255) DEFAULT 'system'
+    status ENUM('verified', 'under_review', 'deprecated', 'archived') DEFAULT 'verified',
+    team_id VARCHAR(255) DEFAULT 'system',
+    content_hash CHAR(64),
+    reviewed_by VARCHAR(255),
+    reviewed_at TIMESTAMP NULL,
+    expires_at TIMESTAMP NULL
 );
 
 CREATE TABLE IF NOT EXISTS memory_links (
@@ -28,14 +32,72 @@ CREATE TABLE IF NOT EXISTS memory_links (
 CREATE TABLE IF NOT EXISTS decisions (
     id VARCHAR(36) PRIMARY KEY,
     task_id VARCHAR(255),
+    owner_id VARCHAR(255),
     memory_ids JSON,
     decision_text TEXT,
-    outcome FLOAT DEFAULT 0.0,
+    status ENUM('proposed', 'active', 'reverted', 'superseded') DEFAULT 'active',
+    rationale TEXT,
+    alternatives JSON,
+    confidence FLOAT NULL DEFAULT NULL,
+    outcome FLOAT NULL DEFAULT NULL,
     feedback TEXT,
+    superseded_by VARCHAR(36),
+    status_reason TEXT,
+    git_commit VARCHAR(40),
+    files JSON,
+    commit_hash VARCHAR(64),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE TABLE IF NOT EXISTS conflicts (
+    id VARCHAR(36) PRIMARY KEY,
+    memory_a VARCHAR(36) NOT NULL,
+    memory_b VARCHAR(36) NOT NULL,
+    team_id VARCHAR(255),
+    similarity FLOAT,
+    method VARCHAR(20),
+    reason TEXT,
+    status ENUM('open', 'resolved', 'dismissed') DEFAULT 'open',
+    resolution VARCHAR(50),
+    detected_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP NULL,
+    UNIQUE KEY uniq_conflict_pair (memory_a, memory_b)
+);
+
+CREATE TABLE IF NOT EXISTS tag_aliases (
+    alias VARCHAR(255) PRIMARY KEY,
+    tag VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS memory_credit (
+    memory_id VARCHAR(36) NOT NULL,
+    decision_id VARCHAR(36) NOT NULL,
+    weight FLOAT NOT NULL,
+    outcome FLOAT NOT NULL,
+    successes FLOAT NOT NULL,
+    failures FLOAT NOT NULL,
+    priority_delta FLOAT NOT NULL,
+    recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (memory_id, decision_id)
+);
+
+CREATE TABLE IF NOT EXISTS pairing_actions (
+    id VARCHAR(36) PRIMARY KEY,
+    task_id VARCHAR(255) NOT NULL,
+    source VARCHAR(255),
+    action TEXT NOT NULL,
+    payload JSON,
+    recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE INDEX idx_memories_category ON memories(category);
 CREATE INDEX idx_memories_priority ON memories(priority DESC);
 CREATE INDEX idx_memories_accessed ON memories(accessed_at DESC);
 CREATE INDEX idx_decisions_outcome ON decisions(outcome DESC);
+CREATE INDEX idx_decisions_status ON decisions(status);
+CREATE INDEX idx_decisions_git_commit ON decisions(git_commit);
+CREATE INDEX idx_memories_status ON memories(status);
+CREATE INDEX idx_memories_expires ON memories(expires_at);
+CREATE INDEX idx_pairing_actions_task ON pairing_actions(task_id);
+CREATE INDEX idx_memories_content_hash ON memories(team_id, content_hash);